@@ -17,7 +17,7 @@ type Client interface {
 	// admin
 	AddPeer(ctx context.Context, nodeURL string) error
 	AdminPeers(ctx context.Context) ([]*p2p.PeerInfo, error)
-	NodeInfo(ctx context.Context) (*p2p.PeerInfo, error)
+	NodeInfo(ctx context.Context) (*p2p.NodeInfo, error)
 
 	// miner
 	StartMining(ctx context.Context) error
@@ -0,0 +1,262 @@
+package eth
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math/big"
+	"strings"
+)
+
+// Decimals of the common ether denominations.
+const (
+	WeiDecimals   = 0
+	GweiDecimals  = 9
+	EtherDecimals = 18
+)
+
+// RoundingMode selects how amounts are rounded when precision is lost.
+type RoundingMode int
+
+const (
+	// RoundDown truncates towards zero.
+	RoundDown RoundingMode = iota
+	// RoundUp rounds away from zero.
+	RoundUp
+	// RoundHalfUp rounds to nearest, ties away from zero.
+	RoundHalfUp
+	// RoundHalfEven rounds to nearest, ties to the even neighbour.
+	RoundHalfEven
+)
+
+// Amount is an exact fixed-point quantity: an integer number of base units,
+// such as wei, and the number of decimals of the whole unit.
+type Amount struct {
+	value    *big.Int
+	decimals int64
+}
+
+// NewAmount creates an amount of value base units with the given decimals. A
+// nil value is zero.
+func NewAmount(value *big.Int, decimals int64) Amount {
+	if value == nil {
+		value = new(big.Int)
+	}
+	return Amount{value: new(big.Int).Set(value), decimals: decimals}
+}
+
+// Wei returns an amount of ether given in wei.
+func Wei(wei *big.Int) Amount {
+	return NewAmount(wei, EtherDecimals)
+}
+
+// ParseAmount parses a decimal string such as "12.5" into an amount with the
+// given decimals. Strings with more fractional digits than decimals are
+// rejected.
+func ParseAmount(s string, decimals int64) (Amount, error) {
+	a, exact, err := parseAmount(s, decimals, RoundDown)
+	if err != nil {
+		return Amount{}, err
+	}
+	if !exact {
+		return Amount{}, fmt.Errorf("amount %q has more than %d decimals", s, decimals)
+	}
+	return a, nil
+}
+
+// ParseAmountRounded parses a decimal string like ParseAmount, rounding extra
+// fractional digits with the given mode.
+func ParseAmountRounded(s string, decimals int64, mode RoundingMode) (Amount, error) {
+	a, _, err := parseAmount(s, decimals, mode)
+	return a, err
+}
+
+// ParseEther parses a decimal number of ether.
+func ParseEther(s string) (Amount, error) {
+	return ParseAmount(s, EtherDecimals)
+}
+
+func parseAmount(s string, decimals int64, mode RoundingMode) (Amount, bool, error) {
+	if decimals < 0 {
+		return Amount{}, false, errors.New("negative decimals")
+	}
+	str := strings.TrimSpace(s)
+	neg := strings.HasPrefix(str, "-")
+	if neg || strings.HasPrefix(str, "+") {
+		str = str[1:]
+	}
+
+	whole, frac := str, ""
+	if i := strings.IndexByte(str, '.'); i >= 0 {
+		whole, frac = str[:i], str[i+1:]
+	}
+	if whole == "" && frac == "" || !isDigits(whole) || !isDigits(frac) {
+		return Amount{}, false, fmt.Errorf("invalid amount %q", s)
+	}
+
+	// Work at full precision, then scale to the requested decimals.
+	value := new(big.Int)
+	if whole+frac != "" {
+		value.SetString(whole+frac, 10)
+	}
+	exact := true
+	if shift := int64(len(frac)) - decimals; shift < 0 {
+		value.Mul(value, bigPow(10, -shift))
+	} else if shift > 0 {
+		exact = strings.TrimRight(frac[decimals:], "0") == ""
+		value = divRound(value, bigPow(10, shift), mode)
+	}
+	if neg {
+		value.Neg(value)
+	}
+	return Amount{value: value, decimals: decimals}, exact, nil
+}
+
+func isDigits(s string) bool {
+	for _, c := range s {
+		if c < '0' || c > '9' {
+			return false
+		}
+	}
+	return true
+}
+
+// divRound divides the non-negative x by y with the given rounding.
+func divRound(x, y *big.Int, mode RoundingMode) *big.Int {
+	q, r := new(big.Int).QuoRem(x, y, new(big.Int))
+	if r.Sign() == 0 {
+		return q
+	}
+	switch mode {
+	case RoundUp:
+		q.Add(q, big.NewInt(1))
+	case RoundHalfUp, RoundHalfEven:
+		switch new(big.Int).Lsh(r, 1).Cmp(y) {
+		case 1:
+			q.Add(q, big.NewInt(1))
+		case 0:
+			if mode == RoundHalfUp || q.Bit(0) == 1 {
+				q.Add(q, big.NewInt(1))
+			}
+		}
+	}
+	return q
+}
+
+// Int returns the amount in base units.
+func (a Amount) Int() *big.Int {
+	if a.value == nil {
+		return new(big.Int)
+	}
+	return new(big.Int).Set(a.value)
+}
+
+// Decimals returns the number of decimals of the amount.
+func (a Amount) Decimals() int64 {
+	return a.decimals
+}
+
+// Sign returns -1, 0 or 1 depending on the sign of the amount.
+func (a Amount) Sign() int {
+	if a.value == nil {
+		return 0
+	}
+	return a.value.Sign()
+}
+
+// Cmp compares two amounts, whatever their decimals.
+func (a Amount) Cmp(b Amount) int {
+	d := max(a.decimals, b.decimals)
+	return a.Rescale(d, RoundDown).value.Cmp(b.Rescale(d, RoundDown).value)
+}
+
+// Rescale converts the amount to another number of decimals, e.g. from wei to
+// gwei, rounding with mode when precision is lost.
+func (a Amount) Rescale(decimals int64, mode RoundingMode) Amount {
+	value := a.Int()
+	switch {
+	case decimals > a.decimals:
+		value.Mul(value, bigPow(10, decimals-a.decimals))
+	case decimals < a.decimals:
+		neg := value.Sign() < 0
+		value = divRound(value.Abs(value), bigPow(10, a.decimals-decimals), mode)
+		if neg {
+			value.Neg(value)
+		}
+	}
+	return Amount{value: value, decimals: decimals}
+}
+
+// Text formats the amount with exactly places fractional digits. Negative
+// places are treated as zero.
+func (a Amount) Text(places int64, mode RoundingMode) string {
+	if places < 0 {
+		places = 0
+	}
+	r := a.Rescale(places, mode)
+	digits := new(big.Int).Abs(r.value).String()
+	sign := ""
+	if r.value.Sign() < 0 {
+		sign = "-"
+	}
+	if places == 0 {
+		return sign + digits
+	}
+	if pad := places + 1 - int64(len(digits)); pad > 0 {
+		digits = strings.Repeat("0", int(pad)) + digits
+	}
+	cut := int64(len(digits)) - places
+	return sign + digits[:cut] + "." + digits[cut:]
+}
+
+// String formats the amount exactly, without trailing fractional zeros but
+// keeping at least one fractional digit, e.g. "1.5" or "2.0". Amounts without
+// decimals are formatted as plain integers.
+func (a Amount) String() string {
+	if a.decimals <= 0 {
+		return a.Int().String()
+	}
+	s := strings.TrimRight(a.Text(a.decimals, RoundDown), "0")
+	if strings.HasSuffix(s, ".") {
+		s += "0"
+	}
+	return s
+}
+
+// Float returns an approximation of the amount, for display only.
+func (a Amount) Float() *big.Float {
+	f := new(big.Float).SetInt(a.Int())
+	return f.Quo(f, new(big.Float).SetInt(bigPow(10, a.decimals)))
+}
+
+// MarshalJSON encodes the amount as a decimal string.
+func (a Amount) MarshalJSON() ([]byte, error) {
+	return json.Marshal(a.String())
+}
+
+// UnmarshalJSON decodes a decimal string or number. An amount created with
+// NewAmount or parsed before keeps its decimals, even zero; the zero Amount
+// takes them from the input.
+func (a *Amount) UnmarshalJSON(data []byte) error {
+	var s string
+	if err := json.Unmarshal(data, &s); err != nil {
+		var n json.Number
+		if err := json.Unmarshal(data, &n); err != nil {
+			return err
+		}
+		s = n.String()
+	}
+	decimals := a.decimals
+	if a.value == nil {
+		decimals = 0
+		if i := strings.IndexByte(s, '.'); i >= 0 {
+			decimals = int64(len(s) - i - 1)
+		}
+	}
+	parsed, err := ParseAmount(s, decimals)
+	if err != nil {
+		return err
+	}
+	*a = parsed
+	return nil
+}
@@ -0,0 +1,134 @@
+package eth
+
+import (
+	"encoding/json"
+	"math/big"
+	"testing"
+)
+
+func TestAmountString(t *testing.T) {
+	huge, _ := new(big.Int).SetString("123456789012345678901234567", 10)
+	tests := []struct {
+		value    *big.Int
+		decimals int64
+		want     string
+	}{
+		{big.NewInt(0), 18, "0.0"},
+		{big.NewInt(1), 18, "0.000000000000000001"},
+		{big.NewInt(1500000000000000000), 18, "1.5"},
+		{big.NewInt(-2000000000000000000), 18, "-2.0"},
+		{huge, 18, "123456789.012345678901234567"},
+		{big.NewInt(42), 0, "42"},
+		{nil, 6, "0.0"},
+	}
+	for _, tt := range tests {
+		if got := NewAmount(tt.value, tt.decimals).String(); got != tt.want {
+			t.Errorf("NewAmount(%v, %d).String() = %q, want %q", tt.value, tt.decimals, got, tt.want)
+		}
+	}
+}
+
+func TestParseAmount(t *testing.T) {
+	a, err := ParseAmount("123456789.012345678901234567", 18)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := "123456789012345678901234567"; a.Int().String() != want {
+		t.Fatalf("base units mismatch: have %s, want %s", a.Int(), want)
+	}
+	if _, err := ParseAmount("1.0000001", 6); err == nil {
+		t.Fatal("expected error for excess decimals")
+	}
+	if a, err := ParseAmount("1.5000000", 6); err != nil || a.Int().Int64() != 1500000 {
+		t.Fatalf("trailing zeros: have %v, %v", a.Int(), err)
+	}
+	for _, bad := range []string{"", ".", "1e18", "0x10", "1.2.3", "--1"} {
+		if _, err := ParseAmount(bad, 18); err == nil {
+			t.Errorf("expected error for %q", bad)
+		}
+	}
+}
+
+func TestAmountRounding(t *testing.T) {
+	tests := []struct {
+		in   string
+		mode RoundingMode
+		want string
+	}{
+		{"1.25", RoundDown, "1.2"},
+		{"1.25", RoundUp, "1.3"},
+		{"1.25", RoundHalfUp, "1.3"},
+		{"1.25", RoundHalfEven, "1.2"},
+		{"1.35", RoundHalfEven, "1.4"},
+		{"-1.25", RoundHalfUp, "-1.3"},
+	}
+	for _, tt := range tests {
+		a, err := ParseAmountRounded(tt.in, 1, tt.mode)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got := a.String(); got != tt.want {
+			t.Errorf("ParseAmountRounded(%q, 1, %d) = %q, want %q", tt.in, tt.mode, got, tt.want)
+		}
+	}
+
+	gwei := Wei(big.NewInt(1500000001)).Rescale(GweiDecimals, RoundUp)
+	if gwei.Int().Int64() != 2 {
+		t.Fatalf("wei to gwei mismatch: have %v, want 2", gwei.Int())
+	}
+}
+
+func TestAmountJSON(t *testing.T) {
+	in := NewAmount(big.NewInt(1050000), 6)
+	data, err := json.Marshal(in)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(data) != `"1.05"` {
+		t.Fatalf("marshal mismatch: have %s", data)
+	}
+	out := NewAmount(nil, 6)
+	if err := json.Unmarshal(data, &out); err != nil {
+		t.Fatal(err)
+	}
+	if out.Cmp(in) != 0 || out.Decimals() != 6 {
+		t.Fatalf("round trip mismatch: have %v (%d decimals)", out, out.Decimals())
+	}
+}
+
+func TestAmountTextNegativePlaces(t *testing.T) {
+	a := NewAmount(big.NewInt(1500), 3)
+	if got := a.Text(-2, RoundHalfUp); got != "2" {
+		t.Fatalf("Text(-2) = %q, want %q", got, "2")
+	}
+}
+
+func TestAmountJSONDecimals(t *testing.T) {
+	// Zero decimals set beforehand are kept.
+	whole := NewAmount(nil, 0)
+	if err := json.Unmarshal([]byte(`"1.5"`), &whole); err == nil {
+		t.Fatalf("expected error decoding a fraction into a whole amount, have %v", whole)
+	}
+	if err := json.Unmarshal([]byte(`42`), &whole); err != nil || whole.Decimals() != 0 || whole.Int().Int64() != 42 {
+		t.Fatalf("whole amount mismatch: have %v (%d decimals), %v", whole, whole.Decimals(), err)
+	}
+	// The zero Amount takes the decimals of the input.
+	var a Amount
+	if err := json.Unmarshal([]byte(`"1.25"`), &a); err != nil {
+		t.Fatal(err)
+	}
+	if a.Decimals() != 2 || a.Int().Int64() != 125 {
+		t.Fatalf("decoded amount mismatch: have %v (%d decimals)", a, a.Decimals())
+	}
+}
+
+func TestTokenBalanceStrings(t *testing.T) {
+	tb := &TokenBalance{Decimals: 6}
+	if tb.ETHString() != "" || tb.BalanceString() != "" {
+		t.Fatalf("unknown balances formatted as %q and %q", tb.ETHString(), tb.BalanceString())
+	}
+	tb.ETH, tb.Balance = big.NewInt(5e17), big.NewInt(1500000)
+	if tb.ETHString() != "0.5" || tb.BalanceString() != "1.5" {
+		t.Fatalf("balances formatted as %q and %q", tb.ETHString(), tb.BalanceString())
+	}
+}
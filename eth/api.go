@@ -24,6 +24,7 @@ import (
 	"github.com/ethereum/go-ethereum/common"
 	"github.com/ethereum/go-ethereum/core/types"
 	"github.com/ethereum/go-ethereum/p2p"
+	"github.com/tokenchain/eth-client/eth/rpc"
 )
 
 type Client interface {
@@ -36,9 +37,14 @@ type Client interface {
 	// admin
 	AddPeer(ctx context.Context, nodeURL string) error
 	AdminPeers(ctx context.Context) ([]*p2p.PeerInfo, error)
-	NodeInfo(ctx context.Context) (*p2p.PeerInfo, error)
+	NodeInfo(ctx context.Context) (*p2p.NodeInfo, error)
 	SupportedModules() (map[string]string, error)
 
+	// raw namespace bindings
+	Admin() rpc.Admin
+	Eth() rpc.Eth
+	TxPool() rpc.TxPool
+
 	// miner
 	StartMining(ctx context.Context) error
 	StopMining(ctx context.Context) error
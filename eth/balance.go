@@ -15,18 +15,38 @@ type TokenBalance struct {
 	ETH            *big.Int
 	Decimals       int64
 	Block          int64
+	BlockHash      common.Hash
 	InternalUserId int64
+	// Err is set when Balance or ETH could not be read. The failed field is
+	// left nil, except for Balance in GetTokenBalance, which reports zero.
+	Err error
+}
+
+// ETHAmount returns the ether balance as an exact amount.
+func (tb *TokenBalance) ETHAmount() Amount {
+	return Wei(tb.ETH)
+}
+
+// BalanceAmount returns the token balance as an exact amount.
+func (tb *TokenBalance) BalanceAmount() Amount {
+	return NewAmount(tb.Balance, tb.Decimals)
 }
 
 func (tb *TokenBalance) ETHString() string {
-	return bigIntString(tb.ETH, 18)
+	if tb.ETH == nil {
+		return ""
+	}
+	return tb.ETHAmount().String()
 }
 
 func (tb *TokenBalance) BalanceString() string {
-	if tb.Decimals == 0 {
-		return tb.Balance.String()
+	if tb.Balance == nil {
+		return ""
+	}
+	if tb.Decimals <= 0 {
+		return tb.BalanceAmount().Int().String()
 	}
-	return bigIntString(tb.Balance, tb.Decimals)
+	return tb.BalanceAmount().String()
 }
 
 func (tb *TokenBalance) ToJSON() string {
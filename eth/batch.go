@@ -0,0 +1,417 @@
+package eth
+
+import (
+	"context"
+	"errors"
+	"math/big"
+	"strings"
+	"sync"
+
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/accounts/abi"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	ethrpc "github.com/ethereum/go-ethereum/rpc"
+)
+
+// multicallABI is the subset of the Multicall3 contract used for bulk reads.
+const multicallABI = "[{\"inputs\":[{\"name\":\"requireSuccess\",\"type\":\"bool\"},{\"components\":[{\"name\":\"target\",\"type\":\"address\"},{\"name\":\"callData\",\"type\":\"bytes\"}],\"name\":\"calls\",\"type\":\"tuple[]\"}],\"name\":\"tryAggregate\",\"outputs\":[{\"components\":[{\"name\":\"success\",\"type\":\"bool\"},{\"name\":\"returnData\",\"type\":\"bytes\"}],\"name\":\"returnData\",\"type\":\"tuple[]\"}],\"stateMutability\":\"payable\",\"type\":\"function\"},{\"inputs\":[{\"name\":\"addr\",\"type\":\"address\"}],\"name\":\"getEthBalance\",\"outputs\":[{\"name\":\"balance\",\"type\":\"uint256\"}],\"stateMutability\":\"view\",\"type\":\"function\"}]"
+
+// DefaultMulticallAddress is where Multicall3 is deployed on mainnet and most
+// public chains. Private networks need to deploy their own.
+var DefaultMulticallAddress = common.HexToAddress("0xcA11bde05977b3631167028862bE2a173976CA11")
+
+const (
+	// maxBatchSize bounds the number of requests in a JSON-RPC batch.
+	maxBatchSize = 100
+	// maxMulticallSize bounds the number of calls aggregated in one eth_call.
+	maxMulticallSize = 500
+)
+
+var (
+	multicallABIOnce   sync.Once
+	multicallABIParsed abi.ABI
+	multicallABIErr    error
+)
+
+func parsedMulticallABI() (abi.ABI, error) {
+	multicallABIOnce.Do(func() {
+		multicallABIParsed, multicallABIErr = abi.JSON(strings.NewReader(multicallABI))
+	})
+	return multicallABIParsed, multicallABIErr
+}
+
+type multicall struct {
+	Target   common.Address
+	CallData []byte
+}
+
+type multicallResult struct {
+	Success    bool
+	ReturnData []byte
+}
+
+// tokenMeta is the static metadata of a token contract.
+type tokenMeta struct {
+	name     string
+	symbol   string
+	decimals int64
+}
+
+// stateRead is a single read of a bulk lookup: either an eth_call of data
+// against contract, or the ether balance of account. done receives the raw
+// return data, a 32 byte big endian integer for ether balances.
+type stateRead struct {
+	contract common.Address
+	data     []byte
+	ether    bool
+	account  common.Address
+	done     func(ret []byte) error
+}
+
+// SetMulticall makes bulk lookups aggregate their reads through the Multicall3
+// contract at addr instead of JSON-RPC batches. Passing nil disables it.
+func (c *ClientTokenEth) SetMulticall(addr *common.Address) {
+	if addr == nil {
+		c.multicall = nil
+		return
+	}
+	mc := *addr
+	c.multicall = &mc
+}
+
+// GetTokenBalances returns the balance of every wallet in every token contract,
+// contract by contract, all read at the same block. The reads are grouped in
+// JSON-RPC batches, or Multicall calls when configured, and the name, symbol
+// and decimals of each contract are only fetched the first time it is seen.
+// An error is returned if a batch cannot be sent; reads failing on their own
+// leave the balance nil and set the Err field of the entry.
+func (c *ClientTokenEth) GetTokenBalances(ctx context.Context, contracts []common.Address, wallets []common.Address, blockNumber *big.Int) ([]*TokenBalance, error) {
+	header, err := c.HeaderByNumber(ctx, blockNumber)
+	if err != nil {
+		return nil, err
+	}
+	pin := newBlockPin(ctx, header)
+
+	metas, err := c.tokenMetas(ctx, contracts, pin)
+	if err != nil {
+		return nil, err
+	}
+	parsed, err := parsedTokenABI()
+	if err != nil {
+		return nil, err
+	}
+
+	var (
+		reads     []*stateRead
+		ethers    = make(map[common.Address]*big.Int)
+		ethErrs   = make(map[common.Address]error)
+		balances  = make([]*TokenBalance, 0, len(contracts)*len(wallets))
+		balanceOf = make(map[*stateRead]*TokenBalance)
+	)
+	for _, wallet := range wallets {
+		if _, ok := ethers[wallet]; ok {
+			continue
+		}
+		ethers[wallet] = nil
+		wallet := wallet
+		reads = append(reads, &stateRead{ether: true, account: wallet, done: func(ret []byte) error {
+			ethers[wallet] = new(big.Int).SetBytes(ret)
+			return nil
+		}})
+	}
+	for _, contract := range contracts {
+		meta := metas[contract]
+		for _, wallet := range wallets {
+			tb := &TokenBalance{
+				Contract:       contract,
+				Wallet:         wallet,
+				Name:           meta.name,
+				Symbol:         meta.symbol,
+				Decimals:       meta.decimals,
+				Block:          header.Number.Int64(),
+				BlockHash:      header.Hash(),
+				InternalUserId: -1,
+			}
+			balances = append(balances, tb)
+
+			data, err := parsed.Pack("balanceOf", wallet)
+			if err != nil {
+				return nil, err
+			}
+			r := &stateRead{contract: contract, data: data, done: func(ret []byte) error {
+				if len(ret) < 32 {
+					return errors.New("short balanceOf return data")
+				}
+				tb.Balance = new(big.Int).SetBytes(ret[:32])
+				return nil
+			}}
+			balanceOf[r] = tb
+			reads = append(reads, r)
+		}
+	}
+
+	errs, err := c.executeReads(ctx, reads, pin)
+	if err != nil {
+		return nil, err
+	}
+	for i, err := range errs {
+		if err == nil {
+			continue
+		}
+		r := reads[i]
+		if r.ether {
+			log.Warn("Failed to get ethereum balance", "wallet", r.account, "err", err)
+			ethErrs[r.account] = err
+		} else {
+			log.Warn("Failed to get token balance", "contract", r.contract, "err", err)
+			balanceOf[r].Err = err
+		}
+	}
+	for _, tb := range balances {
+		tb.ETH = ethers[tb.Wallet]
+		if tb.Err == nil {
+			tb.Err = ethErrs[tb.Wallet]
+		}
+	}
+	return balances, nil
+}
+
+// tokenMetas returns the metadata of the given contracts, fetching the ones
+// not cached yet. Contracts whose decimals cannot be read are reported with
+// decimals -1 and retried on the next lookup.
+func (c *ClientTokenEth) tokenMetas(ctx context.Context, contracts []common.Address, pin *blockPin) (map[common.Address]*tokenMeta, error) {
+	metas := make(map[common.Address]*tokenMeta, len(contracts))
+
+	c.metaMu.Lock()
+	for _, contract := range contracts {
+		if meta, ok := c.tokenMeta[contract]; ok {
+			metas[contract] = meta
+		}
+	}
+	c.metaMu.Unlock()
+
+	parsed, err := parsedTokenABI()
+	if err != nil {
+		return nil, err
+	}
+	var (
+		reads   []*stateRead
+		missing []*tokenMeta
+	)
+	for _, contract := range contracts {
+		if _, ok := metas[contract]; ok {
+			continue
+		}
+		meta := &tokenMeta{
+			name:     "MISSING",
+			symbol:   c.registrySymbol(ctx, contract),
+			decimals: -1,
+		}
+		metas[contract] = meta
+		missing = append(missing, meta)
+
+		for _, method := range []string{"name", "symbol", "decimals"} {
+			data, err := parsed.Pack(method)
+			if err != nil {
+				return nil, err
+			}
+			method := method
+			reads = append(reads, &stateRead{contract: contract, data: data, done: func(ret []byte) error {
+				switch method {
+				case "name":
+					meta.name = decodeTokenString(parsed, method, ret)
+				case "symbol":
+					meta.symbol = decodeTokenString(parsed, method, ret)
+				case "decimals":
+					if len(ret) < 32 {
+						return errors.New("short decimals return data")
+					}
+					meta.decimals = new(big.Int).SetBytes(ret[:32]).Int64()
+				}
+				return nil
+			}})
+		}
+	}
+	if len(reads) == 0 {
+		return metas, nil
+	}
+	errs, err := c.executeReads(ctx, reads, pin)
+	if err != nil {
+		return nil, err
+	}
+	for i, err := range errs {
+		if err != nil {
+			log.Debug("Failed to read token metadata", "contract", reads[i].contract, "err", err)
+		}
+	}
+
+	c.metaMu.Lock()
+	defer c.metaMu.Unlock()
+	if c.tokenMeta == nil {
+		c.tokenMeta = make(map[common.Address]*tokenMeta)
+	}
+	for contract, meta := range metas {
+		if meta.decimals >= 0 {
+			c.tokenMeta[contract] = meta
+		}
+	}
+	return metas, nil
+}
+
+// decodeTokenString decodes a string returned by name or symbol, accepting the
+// bytes32 encoding used by early tokens such as MKR.
+func decodeTokenString(parsed abi.ABI, method string, ret []byte) string {
+	if out, err := parsed.Methods[method].Outputs.Unpack(ret); err == nil && len(out) == 1 {
+		if s, ok := out[0].(string); ok {
+			return s
+		}
+	}
+	if len(ret) == 32 {
+		return strings.TrimRight(string(ret), "\x00")
+	}
+	return "MISSING"
+}
+
+// executeReads performs the reads at the pinned block and returns their errors
+// in the same order. The error is set if a batch could not be performed at all.
+func (c *ClientTokenEth) executeReads(ctx context.Context, reads []*stateRead, pin *blockPin) ([]error, error) {
+	errs := make([]error, len(reads))
+	if c.multicall != nil {
+		for start := 0; start < len(reads); start += maxMulticallSize {
+			end := min(start+maxMulticallSize, len(reads))
+			if err := c.multicallReads(ctx, reads[start:end], pin, errs[start:end]); err != nil {
+				return nil, err
+			}
+		}
+		return errs, nil
+	}
+	for start := 0; start < len(reads); start += maxBatchSize {
+		end := min(start+maxBatchSize, len(reads))
+		if err := c.batchReads(ctx, reads[start:end], pin, errs[start:end]); err != nil {
+			return nil, err
+		}
+	}
+	return errs, nil
+}
+
+// batchReads performs the reads in a single JSON-RPC batch. If every read
+// fails by block hash, the batch is retried by number to find out whether the
+// node rejects EIP-1898 block parameters.
+func (c *ClientTokenEth) batchReads(ctx context.Context, reads []*stateRead, pin *blockPin, errs []error) error {
+	var elems []ethrpc.BatchElem
+	if pin.byHash {
+		elems = readElems(reads, ethrpc.BlockNumberOrHashWithHash(pin.hash, false))
+		if err := c.rpc.BatchCallContext(ctx, elems); err != nil {
+			return err
+		}
+		if allFailed(elems) {
+			byNumber := readElems(reads, toBlockNumArg(pin.number))
+			if err := c.rpc.BatchCallContext(ctx, byNumber); err != nil {
+				return err
+			}
+			if !allFailed(byNumber) {
+				log.Debug("Node rejects batch reads by block hash, pinning by number", "block", pin.number)
+				pin.byHash = false
+				elems = byNumber
+			}
+		}
+	} else {
+		elems = readElems(reads, toBlockNumArg(pin.number))
+		if err := c.rpc.BatchCallContext(ctx, elems); err != nil {
+			return err
+		}
+	}
+	for i, elem := range elems {
+		if elem.Error != nil {
+			errs[i] = elem.Error
+			continue
+		}
+		var ret []byte
+		switch result := elem.Result.(type) {
+		case *hexutil.Big:
+			ret = (*big.Int)(result).Bytes()
+		case *hexutil.Bytes:
+			ret = *result
+		}
+		errs[i] = reads[i].done(ret)
+	}
+	return nil
+}
+
+// readElems builds the batch requests of the reads at block.
+func readElems(reads []*stateRead, block interface{}) []ethrpc.BatchElem {
+	elems := make([]ethrpc.BatchElem, len(reads))
+	for i, r := range reads {
+		if r.ether {
+			elems[i] = ethrpc.BatchElem{
+				Method: "eth_getBalance",
+				Args:   []interface{}{r.account, block},
+				Result: new(hexutil.Big),
+			}
+			continue
+		}
+		elems[i] = ethrpc.BatchElem{
+			Method: "eth_call",
+			Args: []interface{}{map[string]interface{}{
+				"to":   r.contract,
+				"data": hexutil.Bytes(r.data),
+			}, block},
+			Result: new(hexutil.Bytes),
+		}
+	}
+	return elems
+}
+
+func allFailed(elems []ethrpc.BatchElem) bool {
+	for _, elem := range elems {
+		if elem.Error == nil {
+			return false
+		}
+	}
+	return true
+}
+
+// multicallReads performs the reads through a single Multicall3 tryAggregate.
+func (c *ClientTokenEth) multicallReads(ctx context.Context, reads []*stateRead, pin *blockPin, errs []error) error {
+	parsed, err := parsedMulticallABI()
+	if err != nil {
+		return err
+	}
+	calls := make([]multicall, len(reads))
+	for i, r := range reads {
+		if r.ether {
+			data, err := parsed.Pack("getEthBalance", r.account)
+			if err != nil {
+				return err
+			}
+			calls[i] = multicall{Target: *c.multicall, CallData: data}
+			continue
+		}
+		calls[i] = multicall{Target: r.contract, CallData: r.data}
+	}
+	input, err := parsed.Pack("tryAggregate", false, calls)
+	if err != nil {
+		return err
+	}
+	output, err := c.pinnedCallContract(ctx, ethereum.CallMsg{To: c.multicall, Data: input}, pin)
+	if err != nil {
+		return err
+	}
+	out, err := parsed.Unpack("tryAggregate", output)
+	if err != nil {
+		return err
+	}
+	results := *abi.ConvertType(out[0], new([]multicallResult)).(*[]multicallResult)
+	if len(results) != len(reads) {
+		return errors.New("multicall result count mismatch")
+	}
+	for i, res := range results {
+		if !res.Success {
+			errs[i] = errors.New("execution reverted")
+			continue
+		}
+		errs[i] = reads[i].done(res.ReturnData)
+	}
+	return nil
+}
@@ -0,0 +1,182 @@
+package eth
+
+import (
+	"context"
+	"math/big"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/accounts/abi"
+	"github.com/ethereum/go-ethereum/common"
+	ethrpc "github.com/ethereum/go-ethereum/rpc"
+)
+
+var (
+	testTokenA    = common.HexToAddress("0x86fa049857e0209aa7d9e616f7eb3b3b78ecfdb0")
+	testTokenB    = common.HexToAddress("0xd26114cd6ee289accf82350c8d8487fedb8a0c07")
+	testWalletA   = common.HexToAddress("0x3fd3adba69955f85bc34860b77a64c2c52c981ea")
+	testWalletB   = common.HexToAddress("0x2e1d1d3b5e6f7ad7c1a0d8f5e0c8fb2b4c1d5a9e")
+	testMulticall = common.HexToAddress("0x00000000000000000000000000000000000ca11c")
+)
+
+// newBalanceService serves two tokens; token B reverts balanceOf for wallet B.
+func newBalanceService(t *testing.T) *chainService {
+	s := &chainService{
+		headers: testChain(10),
+		balances: map[common.Address]*big.Int{
+			testWalletA: big.NewInt(1e18),
+			testWalletB: big.NewInt(2e18),
+		},
+	}
+	s.call = tokenCalls(t, func(contract common.Address, method string, args []interface{}) (interface{}, error) {
+		switch method {
+		case "name":
+			return "Token", nil
+		case "symbol":
+			return "TKN", nil
+		case "decimals":
+			return big.NewInt(6), nil
+		case "balanceOf":
+			owner := args[0].(common.Address)
+			if contract == testTokenB && owner == testWalletB {
+				return nil, errReverted
+			}
+			return new(big.Int).SetBytes(owner[:2]), nil
+		}
+		return nil, errReverted
+	})
+	return s
+}
+
+// serveMulticall wraps the eth_call handler of s with a Multicall3 contract
+// deployed at addr.
+func serveMulticall(t *testing.T, s *chainService, addr common.Address) {
+	parsed, err := parsedMulticallABI()
+	if err != nil {
+		t.Fatal(err)
+	}
+	token := s.call
+	s.call = func(to common.Address, input []byte) ([]byte, error) {
+		if to != addr {
+			return token(to, input)
+		}
+		args, err := parsed.Methods["tryAggregate"].Inputs.Unpack(input[4:])
+		if err != nil {
+			return nil, err
+		}
+		calls := *abi.ConvertType(args[1], new([]multicall)).(*[]multicall)
+		results := make([]multicallResult, len(calls))
+		for i, call := range calls {
+			var ret []byte
+			if call.Target == addr {
+				args, err := parsed.Methods["getEthBalance"].Inputs.Unpack(call.CallData[4:])
+				if err == nil {
+					ret, err = parsed.Methods["getEthBalance"].Outputs.Pack(s.balances[args[0].(common.Address)])
+				}
+				results[i] = multicallResult{Success: err == nil, ReturnData: ret}
+				continue
+			}
+			ret, err = token(call.Target, call.CallData)
+			results[i] = multicallResult{Success: err == nil, ReturnData: ret}
+		}
+		return parsed.Methods["tryAggregate"].Outputs.Pack(results)
+	}
+}
+
+func TestGetTokenBalances(t *testing.T) {
+	tests := []struct {
+		name         string
+		multicall    bool
+		rejectHashes bool
+	}{
+		{name: "batch"},
+		{name: "batch by number", rejectHashes: true},
+		{name: "multicall", multicall: true},
+		{name: "multicall by number", multicall: true, rejectHashes: true},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			service := newBalanceService(t)
+			service.rejectHashes = test.rejectHashes
+			c := newTestClient(t, map[string]interface{}{"eth": service})
+			if test.multicall {
+				serveMulticall(t, service, testMulticall)
+				mc := testMulticall
+				c.SetMulticall(&mc)
+			}
+
+			contracts := []common.Address{testTokenA, testTokenB}
+			wallets := []common.Address{testWalletA, testWalletB}
+			balances, err := c.GetTokenBalances(context.Background(), contracts, wallets, nil)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if len(balances) != 4 {
+				t.Fatalf("balance count mismatch: have %d, want 4", len(balances))
+			}
+			head := service.headers[9]
+			for _, tb := range balances {
+				if tb.Block != 9 || tb.BlockHash != head.Hash() {
+					t.Errorf("%x/%x: block mismatch: have %d %x", tb.Contract, tb.Wallet, tb.Block, tb.BlockHash)
+				}
+				if tb.Decimals != 6 || tb.Symbol != "TKN" {
+					t.Errorf("%x/%x: metadata mismatch: %+v", tb.Contract, tb.Wallet, tb)
+				}
+				if tb.ETH == nil || tb.ETH.Cmp(service.balances[tb.Wallet]) != 0 {
+					t.Errorf("%x/%x: ether balance mismatch: have %v", tb.Contract, tb.Wallet, tb.ETH)
+				}
+				if tb.Contract == testTokenB && tb.Wallet == testWalletB {
+					if tb.Balance != nil || tb.Err == nil {
+						t.Errorf("failed read: have balance %v, err %v", tb.Balance, tb.Err)
+					}
+					continue
+				}
+				want := new(big.Int).SetBytes(tb.Wallet[:2])
+				if tb.Err != nil || tb.Balance == nil || tb.Balance.Cmp(want) != 0 {
+					t.Errorf("%x/%x: balance mismatch: have %v (err %v), want %v", tb.Contract, tb.Wallet, tb.Balance, tb.Err, want)
+				}
+			}
+
+			// Every read is made at the head, by hash unless rejected.
+			for _, block := range service.readBlocks() {
+				hash, byHash := block.Hash()
+				number, _ := block.Number()
+				switch {
+				case test.rejectHashes && byHash:
+					t.Fatalf("read by hash on a node rejecting them")
+				case byHash && hash != head.Hash():
+					t.Fatalf("read pinned to hash %x, want %x", hash, head.Hash())
+				case !byHash && number != 9:
+					t.Fatalf("read at block %d, want 9", number)
+				case !byHash && !test.rejectHashes:
+					t.Fatalf("read by number on a node accepting hashes")
+				}
+			}
+		})
+	}
+}
+
+func TestExecuteReadsTransportFailure(t *testing.T) {
+	service := newBalanceService(t)
+	c := newTestClient(t, map[string]interface{}{"eth": service})
+	pin := newBlockPin(context.Background(), service.headers[9])
+	reads := []*stateRead{{ether: true, account: testWalletA, done: func([]byte) error { return nil }}}
+
+	c.Close()
+	if _, err := c.executeReads(context.Background(), reads, pin); err != ethrpc.ErrClientQuit {
+		t.Fatalf("batch error mismatch: have %v, want %v", err, ethrpc.ErrClientQuit)
+	}
+}
+
+func TestMulticallReadsFailure(t *testing.T) {
+	service := newBalanceService(t) // no multicall contract deployed
+	c := newTestClient(t, map[string]interface{}{"eth": service})
+	mc := testMulticall
+	c.SetMulticall(&mc)
+	pin := newBlockPin(context.Background(), service.headers[9])
+	reads := []*stateRead{{ether: true, account: testWalletA, done: func([]byte) error { return nil }}}
+
+	errs, err := c.executeReads(context.Background(), reads, pin)
+	if err == nil {
+		t.Fatalf("expected error for a failing aggregate, have read errors %v", errs)
+	}
+}
@@ -0,0 +1,244 @@
+package eth
+
+import (
+	"context"
+	"errors"
+	"math/big"
+	"sync"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+)
+
+// ErrReorgTooDeep is returned when a new head does not connect to any header
+// in the tracker's window within as many blocks as the window holds, or when
+// none of the blocks a Scanner remembers is canonical anymore.
+var ErrReorgTooDeep = errors.New("reorg deeper than the tracked window")
+
+// ChainEventKind tells applied blocks from reverted ones.
+type ChainEventKind int
+
+const (
+	// BlockApplied reports a block that joined the canonical chain.
+	BlockApplied ChainEventKind = iota
+	// BlockReverted reports a block that left the canonical chain.
+	BlockReverted
+)
+
+func (k ChainEventKind) String() string {
+	if k == BlockReverted {
+		return "reverted"
+	}
+	return "applied"
+}
+
+// ChainEvent is a change of the canonical chain. On a reorg the orphaned
+// blocks are reported first, newest first, followed by the new blocks,
+// oldest first.
+type ChainEvent struct {
+	Kind   ChainEventKind
+	Header *types.Header
+}
+
+// HeaderSource is the part of the client the chain tracker fetches headers
+// from.
+type HeaderSource interface {
+	HeaderByHash(ctx context.Context, hash common.Hash) (*types.Header, error)
+	HeaderByNumber(ctx context.Context, number *big.Int) (*types.Header, error)
+}
+
+// ChainTracker follows the canonical chain through a window of recent
+// headers. Every new head is linked to its parent; when the parent is not the
+// tracked head, the tracker walks back to the common ancestor and reports the
+// reverted and applied blocks.
+type ChainTracker struct {
+	source HeaderSource
+	window int
+
+	mu      sync.Mutex
+	headers []*types.Header // canonical headers, oldest first, contiguous
+}
+
+// NewChainTracker creates a tracker remembering the last window headers.
+func NewChainTracker(source HeaderSource, window int) *ChainTracker {
+	if window < 1 {
+		window = 1
+	}
+	return &ChainTracker{source: source, window: window}
+}
+
+// NewChainTracker creates a chain tracker reading headers through the client.
+func (c *ClientTokenEth) NewChainTracker(window int) *ChainTracker {
+	return NewChainTracker(c, window)
+}
+
+// Head returns the tracked head, or nil before the first update.
+func (t *ChainTracker) Head() *types.Header {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if len(t.headers) == 0 {
+		return nil
+	}
+	return t.headers[len(t.headers)-1]
+}
+
+// Canonical reports whether hash is a tracked canonical block.
+func (t *ChainTracker) Canonical(hash common.Hash) bool {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	for _, h := range t.headers {
+		if h.Hash() == hash {
+			return true
+		}
+	}
+	return false
+}
+
+// Update moves the tracker to head and returns the resulting chain events.
+// Blocks between the previous head and head that were never seen, e.g.
+// because a subscription skipped them, are fetched and reported as applied.
+// A head already in the window, such as one delivered late, is ignored.
+func (t *ChainTracker) Update(ctx context.Context, head *types.Header) ([]ChainEvent, error) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	if len(t.headers) == 0 {
+		t.headers = []*types.Header{head}
+		return []ChainEvent{{Kind: BlockApplied, Header: head}}, nil
+	}
+	if t.index(head.Hash()) >= 0 {
+		return nil, nil
+	}
+
+	// Walk the new branch back until it meets a tracked header.
+	var (
+		branch   = []*types.Header{head}
+		ancestor = -1
+	)
+	for ancestor < 0 {
+		oldest := branch[len(branch)-1]
+		if i := t.index(oldest.ParentHash); i >= 0 {
+			ancestor = i
+			break
+		}
+		if oldest.Number.Cmp(t.headers[0].Number) <= 0 || len(branch) >= t.window {
+			return nil, ErrReorgTooDeep
+		}
+		parent, err := t.source.HeaderByHash(ctx, oldest.ParentHash)
+		if err != nil {
+			return nil, err
+		}
+		branch = append(branch, parent)
+	}
+
+	var events []ChainEvent
+	for i := len(t.headers) - 1; i > ancestor; i-- {
+		events = append(events, ChainEvent{Kind: BlockReverted, Header: t.headers[i]})
+	}
+	t.headers = t.headers[:ancestor+1]
+	for i := len(branch) - 1; i >= 0; i-- {
+		events = append(events, ChainEvent{Kind: BlockApplied, Header: branch[i]})
+		t.headers = append(t.headers, branch[i])
+	}
+	if n := len(t.headers); n > t.window {
+		t.headers = append([]*types.Header(nil), t.headers[n-t.window:]...)
+	}
+	return events, nil
+}
+
+// Poll fetches the latest header and updates the tracker with it.
+func (t *ChainTracker) Poll(ctx context.Context) ([]ChainEvent, error) {
+	head, err := t.source.HeaderByNumber(ctx, nil)
+	if err != nil {
+		return nil, err
+	}
+	return t.Update(ctx, head)
+}
+
+// index returns the position of hash in the window, or -1.
+func (t *ChainTracker) index(hash common.Hash) int {
+	for i := len(t.headers) - 1; i >= 0; i-- {
+		if t.headers[i].Hash() == hash {
+			return i
+		}
+	}
+	return -1
+}
+
+// Follow subscribes to new heads, falling back to polling every interval on
+// endpoints without subscriptions, and sends the resulting chain events to
+// ch until ctx is cancelled or an error occurs.
+func (t *ChainTracker) Follow(ctx context.Context, client *ClientTokenEth, ch chan<- ChainEvent) error {
+	heads := make(chan *types.Header, 16)
+	var (
+		tick   <-chan struct{}
+		subErr <-chan error
+	)
+	sub, err := client.SubscribeNewHead(ctx, heads)
+	if err != nil {
+		tick = pollTicker(ctx, defaultPollInterval)
+	} else {
+		defer sub.Unsubscribe()
+		subErr = sub.Err()
+	}
+
+	deliver := func(events []ChainEvent) error {
+		for _, ev := range events {
+			select {
+			case ch <- ev:
+			case <-ctx.Done():
+				return ctx.Err()
+			}
+		}
+		return nil
+	}
+	for {
+		var (
+			events []ChainEvent
+			err    error
+		)
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case head := <-heads:
+			events, err = t.Update(ctx, head)
+		case <-tick:
+			events, err = t.Poll(ctx)
+		case err := <-subErr:
+			log.Debug("Head subscription lost, polling instead", "err", err)
+			subErr = nil
+			tick = pollTicker(ctx, defaultPollInterval)
+			continue
+		}
+		if err != nil {
+			return err
+		}
+		if err := deliver(events); err != nil {
+			return err
+		}
+	}
+}
+
+// pollTicker returns a channel firing immediately and then every interval
+// until ctx is cancelled.
+func pollTicker(ctx context.Context, interval time.Duration) <-chan struct{} {
+	ch := make(chan struct{}, 1)
+	ch <- struct{}{}
+	go func() {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+				select {
+				case ch <- struct{}{}:
+				default:
+				}
+			}
+		}
+	}()
+	return ch
+}
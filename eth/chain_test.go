@@ -0,0 +1,172 @@
+package eth
+
+import (
+	"context"
+	"math/big"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+)
+
+type headerMap map[common.Hash]*types.Header
+
+func (m headerMap) HeaderByHash(ctx context.Context, hash common.Hash) (*types.Header, error) {
+	return m[hash], nil
+}
+
+func (m headerMap) HeaderByNumber(ctx context.Context, number *big.Int) (*types.Header, error) {
+	return nil, nil
+}
+
+// makeChain extends parent by n headers, tagging them with extra to create
+// distinct forks.
+func makeChain(m headerMap, parent *types.Header, n int, extra byte) []*types.Header {
+	var chain []*types.Header
+	for i := 0; i < n; i++ {
+		h := &types.Header{
+			ParentHash: parent.Hash(),
+			Number:     new(big.Int).Add(parent.Number, big.NewInt(1)),
+			Extra:      []byte{extra},
+		}
+		m[h.Hash()] = h
+		chain = append(chain, h)
+		parent = h
+	}
+	return chain
+}
+
+func TestChainTrackerReorg(t *testing.T) {
+	var (
+		ctx     = context.Background()
+		m       = make(headerMap)
+		genesis = &types.Header{Number: big.NewInt(0)}
+		tracker = NewChainTracker(m, 16)
+	)
+	m[genesis.Hash()] = genesis
+	main := makeChain(m, genesis, 3, 'a')
+
+	if _, err := tracker.Update(ctx, genesis); err != nil {
+		t.Fatal(err)
+	}
+	// Skipped heads are fetched and applied in order.
+	events, err := tracker.Update(ctx, main[2])
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(events) != 3 || events[0].Header != main[0] || events[2].Header != main[2] {
+		t.Fatalf("unexpected events filling the gap: %v", events)
+	}
+
+	// A longer fork from block 1 reverts blocks 3 and 2.
+	fork := makeChain(m, main[0], 3, 'b')
+	events, err = tracker.Update(ctx, fork[2])
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []ChainEvent{
+		{BlockReverted, main[2]},
+		{BlockReverted, main[1]},
+		{BlockApplied, fork[0]},
+		{BlockApplied, fork[1]},
+		{BlockApplied, fork[2]},
+	}
+	if len(events) != len(want) {
+		t.Fatalf("event count mismatch: have %d, want %d", len(events), len(want))
+	}
+	for i := range want {
+		if events[i].Kind != want[i].Kind || events[i].Header.Hash() != want[i].Header.Hash() {
+			t.Errorf("event %d: have %v %v, want %v %v", i, events[i].Kind, events[i].Header.Number, want[i].Kind, want[i].Header.Number)
+		}
+	}
+	if tracker.Canonical(main[1].Hash()) {
+		t.Error("reverted block still canonical")
+	}
+	if tracker.Head() != fork[2] {
+		t.Error("head not moved to the fork")
+	}
+}
+
+func TestChainTrackerTooDeep(t *testing.T) {
+	var (
+		ctx     = context.Background()
+		m       = make(headerMap)
+		genesis = &types.Header{Number: big.NewInt(0)}
+		tracker = NewChainTracker(m, 2)
+	)
+	m[genesis.Hash()] = genesis
+	main := makeChain(m, genesis, 4, 'a')
+	for _, h := range main {
+		if _, err := tracker.Update(ctx, h); err != nil {
+			t.Fatal(err)
+		}
+	}
+	fork := makeChain(m, genesis, 5, 'b')
+	if _, err := tracker.Update(ctx, fork[4]); err != ErrReorgTooDeep {
+		t.Fatalf("error mismatch: have %v, want %v", err, ErrReorgTooDeep)
+	}
+}
+
+func TestChainTrackerStaleHead(t *testing.T) {
+	var (
+		ctx     = context.Background()
+		m       = make(headerMap)
+		genesis = &types.Header{Number: big.NewInt(0)}
+		tracker = NewChainTracker(m, 16)
+	)
+	m[genesis.Hash()] = genesis
+	main := makeChain(m, genesis, 3, 'a')
+	for _, h := range append([]*types.Header{genesis}, main...) {
+		if _, err := tracker.Update(ctx, h); err != nil {
+			t.Fatal(err)
+		}
+	}
+	// A late head already in the window changes nothing.
+	events, err := tracker.Update(ctx, main[1])
+	if err != nil || events != nil {
+		t.Fatalf("stale head: have events %v, err %v", events, err)
+	}
+	if tracker.Head() != main[2] {
+		t.Fatal("stale head moved the tracker")
+	}
+}
+
+// countingSource counts the headers fetched by hash.
+type countingSource struct {
+	headerMap
+	fetched int
+}
+
+func (s *countingSource) HeaderByHash(ctx context.Context, hash common.Hash) (*types.Header, error) {
+	s.fetched++
+	return s.headerMap[hash], nil
+}
+
+func TestChainTrackerGapTooLong(t *testing.T) {
+	var (
+		ctx     = context.Background()
+		m       = make(headerMap)
+		source  = &countingSource{headerMap: m}
+		genesis = &types.Header{Number: big.NewInt(0)}
+		tracker = NewChainTracker(source, 4)
+	)
+	m[genesis.Hash()] = genesis
+	main := makeChain(m, genesis, 10, 'a')
+	if _, err := tracker.Update(ctx, genesis); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := tracker.Update(ctx, main[9]); err != ErrReorgTooDeep {
+		t.Fatalf("error mismatch: have %v, want %v", err, ErrReorgTooDeep)
+	}
+	if source.fetched >= 4 {
+		t.Fatalf("walked %d headers, more than the window allows", source.fetched)
+	}
+	// A gap within the window is still filled.
+	events, err := tracker.Update(ctx, main[2])
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(events) != 3 || events[2].Header != main[2] {
+		t.Fatalf("unexpected events filling the gap: %v", events)
+	}
+}
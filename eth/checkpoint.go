@@ -0,0 +1,213 @@
+package eth
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"math/big"
+	"os"
+	"path/filepath"
+	"runtime"
+	"sync"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+)
+
+var (
+	// ErrCheckpointNotFound is returned when a consumer has no checkpoint yet.
+	ErrCheckpointNotFound = errors.New("checkpoint not found")
+	// ErrCheckpointNotCanonical is returned when the block of a checkpoint was
+	// reorganised out of the canonical chain.
+	ErrCheckpointNotCanonical = errors.New("checkpoint block is no longer canonical")
+)
+
+// Checkpoint is the last block a consumer fully processed.
+type Checkpoint struct {
+	Number    uint64      `json:"number"`
+	Hash      common.Hash `json:"hash"`
+	UpdatedAt time.Time   `json:"updatedAt"`
+}
+
+// CheckpointStore persists checkpoints of named consumers.
+type CheckpointStore interface {
+	// Load returns the checkpoint of the consumer, or ErrCheckpointNotFound.
+	Load(name string) (Checkpoint, error)
+	// Save replaces the checkpoint of the consumer.
+	Save(name string, cp Checkpoint) error
+	// Delete forgets the checkpoint of the consumer.
+	Delete(name string) error
+}
+
+// MemoryCheckpointStore keeps checkpoints in memory, for tests and
+// short-lived processes.
+type MemoryCheckpointStore struct {
+	mu          sync.Mutex
+	checkpoints map[string]Checkpoint
+}
+
+// NewMemoryCheckpointStore creates an empty in-memory store.
+func NewMemoryCheckpointStore() *MemoryCheckpointStore {
+	return &MemoryCheckpointStore{checkpoints: make(map[string]Checkpoint)}
+}
+
+func (s *MemoryCheckpointStore) Load(name string) (Checkpoint, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	cp, ok := s.checkpoints[name]
+	if !ok {
+		return Checkpoint{}, ErrCheckpointNotFound
+	}
+	return cp, nil
+}
+
+func (s *MemoryCheckpointStore) Save(name string, cp Checkpoint) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.checkpoints[name] = cp
+	return nil
+}
+
+func (s *MemoryCheckpointStore) Delete(name string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	delete(s.checkpoints, name)
+	return nil
+}
+
+// FileCheckpointStore keeps all checkpoints in a single JSON file. Every save
+// writes a temporary file and renames it over the previous one, so a crash
+// never leaves a torn file behind, then syncs the directory so the rename
+// itself survives a crash.
+type FileCheckpointStore struct {
+	path string
+
+	mu          sync.Mutex
+	checkpoints map[string]Checkpoint
+}
+
+// OpenFileCheckpointStore opens the store at path, creating it on first save.
+func OpenFileCheckpointStore(path string) (*FileCheckpointStore, error) {
+	s := &FileCheckpointStore{path: path, checkpoints: make(map[string]Checkpoint)}
+	data, err := os.ReadFile(path)
+	switch {
+	case os.IsNotExist(err):
+		return s, nil
+	case err != nil:
+		return nil, err
+	}
+	if err := json.Unmarshal(data, &s.checkpoints); err != nil {
+		return nil, err
+	}
+	return s, nil
+}
+
+func (s *FileCheckpointStore) Load(name string) (Checkpoint, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	cp, ok := s.checkpoints[name]
+	if !ok {
+		return Checkpoint{}, ErrCheckpointNotFound
+	}
+	return cp, nil
+}
+
+func (s *FileCheckpointStore) Save(name string, cp Checkpoint) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	prev, existed := s.checkpoints[name]
+	s.checkpoints[name] = cp
+	if err := s.flush(); err != nil {
+		if existed {
+			s.checkpoints[name] = prev
+		} else {
+			delete(s.checkpoints, name)
+		}
+		return err
+	}
+	return nil
+}
+
+func (s *FileCheckpointStore) Delete(name string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	prev, existed := s.checkpoints[name]
+	if !existed {
+		return nil
+	}
+	delete(s.checkpoints, name)
+	if err := s.flush(); err != nil {
+		s.checkpoints[name] = prev
+		return err
+	}
+	return nil
+}
+
+func (s *FileCheckpointStore) flush() error {
+	data, err := json.MarshalIndent(s.checkpoints, "", "  ")
+	if err != nil {
+		return err
+	}
+	tmp, err := os.CreateTemp(filepath.Dir(s.path), filepath.Base(s.path)+".tmp*")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Sync(); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+	if err := os.Rename(tmp.Name(), s.path); err != nil {
+		return err
+	}
+	return syncDir(filepath.Dir(s.path))
+}
+
+// syncDir flushes the directory entries of dir to disk. Windows cannot sync
+// directories and commits renames on its own.
+func syncDir(dir string) error {
+	if runtime.GOOS == "windows" {
+		return nil
+	}
+	d, err := os.Open(dir)
+	if err != nil {
+		return err
+	}
+	if err := d.Sync(); err != nil {
+		d.Close()
+		return err
+	}
+	return d.Close()
+}
+
+// NewCheckpoint returns a checkpoint for header, stamped with the current time.
+func NewCheckpoint(header *types.Header) Checkpoint {
+	return Checkpoint{
+		Number:    header.Number.Uint64(),
+		Hash:      header.Hash(),
+		UpdatedAt: time.Now(),
+	}
+}
+
+// VerifyCheckpoint checks that the checkpoint block is still part of the
+// canonical chain, returning ErrCheckpointNotCanonical if it was reorganised
+// away.
+func (c *ClientTokenEth) VerifyCheckpoint(ctx context.Context, cp Checkpoint) error {
+	header, err := c.HeaderByNumber(ctx, new(big.Int).SetUint64(cp.Number))
+	if err != nil {
+		return err
+	}
+	if header.Hash() != cp.Hash {
+		return ErrCheckpointNotCanonical
+	}
+	return nil
+}
@@ -0,0 +1,49 @@
+package eth
+
+import (
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+)
+
+func TestFileCheckpointStore(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "checkpoints.json")
+	store, err := OpenFileCheckpointStore(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := store.Load("deposits"); err != ErrCheckpointNotFound {
+		t.Fatalf("error mismatch: have %v, want %v", err, ErrCheckpointNotFound)
+	}
+
+	cp := Checkpoint{
+		Number:    2470038,
+		Hash:      common.HexToHash("0xbf87cbdf6d36b734def2c2b3770d735b56dcafda54f296955531cebdc6065d0b"),
+		UpdatedAt: time.Unix(1540000000, 0).UTC(),
+	}
+	if err := store.Save("deposits", cp); err != nil {
+		t.Fatal(err)
+	}
+
+	// A reopened store sees the saved checkpoint.
+	reopened, err := OpenFileCheckpointStore(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	got, err := reopened.Load("deposits")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got.Number != cp.Number || got.Hash != cp.Hash || !got.UpdatedAt.Equal(cp.UpdatedAt) {
+		t.Fatalf("checkpoint mismatch: have %+v, want %+v", got, cp)
+	}
+
+	if err := reopened.Delete("deposits"); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := reopened.Load("deposits"); err != ErrCheckpointNotFound {
+		t.Fatalf("error mismatch after delete: have %v, want %v", err, ErrCheckpointNotFound)
+	}
+}
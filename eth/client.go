@@ -24,7 +24,9 @@ import (
 	"encoding/hex"
 	"encoding/json"
 	"math/big"
+	"sync"
 
+	"github.com/ethereum/go-ethereum/accounts/abi/bind"
 	"github.com/ethereum/go-ethereum/common"
 	"github.com/ethereum/go-ethereum/common/hexutil"
 	"github.com/ethereum/go-ethereum/core/types"
@@ -40,8 +42,25 @@ import (
 // client defines typed wrappers for the Ethereum RPC API.
 type ClientTokenEth struct {
 	*ethclient.Client
-	rpc   *ethrpc.Client
-	admin rpc.Admin
+	rpc    *ethrpc.Client
+	admin  rpc.Admin
+	eth    rpc.Eth
+	txpool rpc.TxPool
+
+	// chainMu guards the lazily discovered chain ID used for signing.
+	chainMu sync.Mutex
+	chainID *big.Int
+
+	feeStrategy *FeeStrategy
+	nonces      *NonceManager
+	multicall   *common.Address
+
+	// metaMu guards the static token metadata cached by bulk lookups.
+	metaMu    sync.Mutex
+	tokenMeta map[common.Address]*tokenMeta
+
+	tokens        *TokenRegistry
+	verifySenders bool
 }
 
 
@@ -67,13 +86,28 @@ func Dial(rawurl string) (*ClientTokenEth, error) {
 }
 
 // NewClient creates a client that uses the given RPC client.
-func NewClient(rpc *ethrpc.Client) *ClientTokenEth {
+func NewClient(rc *ethrpc.Client) *ClientTokenEth {
 	return &ClientTokenEth{
-		Client: ethclient.NewClient(rpc),
-		rpc:    rpc,
+		Client: ethclient.NewClient(rc),
+		rpc:    rc,
+		admin:  rpc.NewAdmin(rc),
+		eth:    rpc.NewEth(rc),
+		txpool: rpc.NewTxPool(rc),
+		nonces: NewNonceManager(),
+		tokens: DefaultTokenRegistry(),
 	}
 }
 
+// Admin returns the bindings of the admin namespace.
+func (c *ClientTokenEth) Admin() rpc.Admin {
+	return c.admin
+}
+
+// Eth returns the raw bindings of the eth namespace.
+func (c *ClientTokenEth) Eth() rpc.Eth {
+	return c.eth
+}
+
 // Close closes an existing RPC connection.
 func (c *ClientTokenEth) Close() {
 	c.rpc.Close()
@@ -119,34 +153,24 @@ func toBlockNumArg(number *big.Int) string {
 
 // AddPeer connects to the given nodeURL.
 func (c *ClientTokenEth) AddPeer(ctx context.Context, nodeURL string) error {
-	var r bool
-	// TODO: Result needs to be verified
-	err := c.rpc.CallContext(ctx, &r, "admin_addPeer", nodeURL)
+	ok, err := c.admin.AddPeer(ctx, nodeURL)
 	if err != nil {
 		return err
 	}
-	return err
+	if !ok {
+		return ErrPeerRejected
+	}
+	return nil
 }
 
-// AdminPeers returns the number of connected peers.
+// AdminPeers returns the connected peers.
 func (c *ClientTokenEth) AdminPeers(ctx context.Context) ([]*p2p.PeerInfo, error) {
-	var r []*p2p.PeerInfo
-	// The response data type are bytes, but we cannot parse...
-	err := c.rpc.CallContext(ctx, &r, "admin_peers")
-	if err != nil {
-		return nil, err
-	}
-	return r, err
+	return c.admin.Peers(ctx)
 }
 
 // NodeInfo gathers and returns a collection of metadata known about the host.
-func (c *ClientTokenEth) NodeInfo(ctx context.Context) (*p2p.PeerInfo, error) {
-	var r *p2p.PeerInfo
-	err := c.rpc.CallContext(ctx, &r, "admin_nodeInfo")
-	if err != nil {
-		return nil, err
-	}
-	return r, err
+func (c *ClientTokenEth) NodeInfo(ctx context.Context) (*p2p.NodeInfo, error) {
+	return c.admin.NodeInfo(ctx)
 }
 
 // ----------------------------------------------------------------------------
@@ -188,7 +212,7 @@ func (c *ClientTokenEth) StopMining(ctx context.Context) error {
 // Generic client.Client functions
 func (c *ClientTokenEth) GetInfo(ctx context.Context) (string, error) {
 	type info struct {
-		NodeInfo    *p2p.PeerInfo   `json:"nodeInfo"`
+		NodeInfo    *p2p.NodeInfo   `json:"nodeInfo"`
 		AdminPeers  []*p2p.PeerInfo `json:"adminPeers"`
 		BlockNumber string          `json:"blockNumber"`
 	}
@@ -231,45 +255,51 @@ func privateKeyToPubAddress(privKey *ecdsa.PrivateKey) (*common.Address, error)
 	return &pubAddress, nil
 }
 
+// SendAmount transfers amount wei from the account of fromPriv to toPub.
 func (c *ClientTokenEth) SendAmount(ctx context.Context, fromPriv, toPub, amount string) error {
+	_, err := c.SendAmountTx(ctx, fromPriv, toPub, amount)
+	return err
+}
+
+// SendAmountTx transfers amount wei from the account of fromPriv to toPub and
+// returns a handle to follow the broadcast transaction.
+func (c *ClientTokenEth) SendAmountTx(ctx context.Context, fromPriv, toPub, amount string) (*PendingTransaction, error) {
 	fromPrivKey, err := crypto.HexToECDSA(fromPriv)
 	if err != nil {
-		return err
+		return nil, err
 	}
 
 	// convert fromPrivKey to fromPubAddress
 	fromPubAddress, err := privateKeyToPubAddress(fromPrivKey)
 	if err != nil {
-		return err
+		return nil, err
 	}
 
 	toAddress := common.HexToAddress(toPub)
 
-	amountInt := new(big.Int)
-	amountInt.SetString(amount, 10)
+	amountInt, ok := new(big.Int).SetString(amount, 10)
+	if !ok {
+		return nil, fmt.Errorf("invalid amount %q", amount)
+	}
 
-	// find a gas price
-	gasPrice, err := c.SuggestGasPrice(ctx)
+	// price the transfer, EIP-1559 where the chain supports it
+	fees, err := c.SuggestFees(ctx)
 	if err != nil {
-		return err
+		return nil, err
 	}
 
-	// find pending nonce for from account
-	nonce, err := c.PendingNonceAt(ctx, *fromPubAddress)
+	gas, err := c.estimateGas(ctx, *fromPubAddress, &toAddress, amountInt, nil, fees)
 	if err != nil {
-		return err
+		return nil, err
 	}
 
-	// assume gas limit of 21,000
-	tx := types.NewTransaction(nonce, toAddress, amountInt,
-		21000, gasPrice, nil)
-
-	signTx, err := types.SignTx(tx, types.HomesteadSigner{}, fromPrivKey)
+	chainID, err := c.SignerChainID(ctx)
 	if err != nil {
-		return err
+		return nil, err
 	}
-
-	return c.SendTransaction(ctx, signTx)
+	return c.sendWithNextNonce(ctx, fromPrivKey, func(nonce uint64) (*types.Transaction, error) {
+		return newTransaction(chainID, nonce, &toAddress, amountInt, gas, fees, nil), nil
+	})
 }
 
 func (c *ClientTokenEth) GetBalance(ctx context.Context, account string) (string, error) {
@@ -294,18 +324,19 @@ func (c *ClientTokenEth) TransactionCountByBlockNumber(ctx context.Context, numb
 	return uint(num), err
 }
 
+// TransactionByBlockNumberIndex returns the transaction at index in the given
+// block. With sender verification enabled, a transaction whose signature does
+// not match the reported sender is returned along with a *SenderMismatchError.
 func (c *ClientTokenEth) TransactionByBlockNumberIndex(ctx context.Context, number *big.Int, index *big.Int) (*RpcEthTransaction, error) {
 	var json *RpcEthTransaction
 	err := c.rpc.CallContext(ctx, &json, "eth_getTransactionByBlockNumberAndIndex", toBlockNumArg(number), toBlockNumArg(index))
-	if err == nil {
-		if json == nil {
-			return nil, ethereum.NotFound
-		}
+	if err != nil {
+		return nil, err
+	}
+	if json == nil {
+		return nil, ethereum.NotFound
 	}
-	//if json.From != nil && json.BlockHash != nil {
-	//setSenderFromServer(json.tx, *json.From, *json.BlockHash)
-	//}
-	return json, err
+	return json, c.checkSender(ctx, json)
 }
 func (c *ClientTokenEth) GetTokenBalanceLatest(ctx context.Context, token_contract common.Address, account_wallet common.Address) (*TokenBalance, error) {
 	return c.GetTokenBalance(ctx, token_contract, account_wallet, nil)
@@ -326,43 +357,62 @@ func (c *ClientTokenEth) GetTokenBalance(ctx context.Context, token_contract com
 		return tb, err
 	}
 
-	block, err := c.BlockByNumber(ctx, blockNumber)
+	// resolve the block once, every read below is pinned to it
+	header, err := c.HeaderByNumber(ctx, blockNumber)
 	if err != nil {
 		spl := fmt.Sprintf("Failed to get current block number: %v\n", err)
 		fmt.Println(spl)
 		return tb, err
 	}
-	tb.Block = block.Number().Int64()
-
-	decimals, err := token.Decimals(nil)
+	pin := newBlockPin(ctx, header)
+	tb.Block = header.Number.Int64()
+	tb.BlockHash = header.Hash()
+
+	err = pin.call(func(opts *bind.CallOpts) (err error) {
+		decimals, err := token.Decimals(opts)
+		if err == nil {
+			tb.Decimals = decimals.Int64()
+		}
+		return err
+	})
 	if err != nil {
 		spl := fmt.Sprintf("Failed to get decimals from contract: %v \n", tb.Contract.String())
 		fmt.Println(spl)
 		return tb, err
 	}
-	tb.Decimals = decimals.Int64()
 
-	tb.ETH, err = c.BalanceAt(ctx, account_wallet, nil)
+	tb.ETH, err = c.pinnedBalanceAt(ctx, account_wallet, pin)
 	if err != nil {
 		spl := fmt.Sprintf("Failed to get ethereum balance from address: %v \n", tb.Wallet.String())
 		fmt.Println(spl)
+		tb.Err = err
 	}
 
-	tb.Balance, err = token.BalanceOf(nil, account_wallet)
+	err = pin.call(func(opts *bind.CallOpts) (err error) {
+		tb.Balance, err = token.BalanceOf(opts, account_wallet)
+		return err
+	})
 	if err != nil {
 		spl := fmt.Sprintf("Failed to get balance from contract: %v %v\n", tb.Contract.String(), err)
 		fmt.Println(spl)
-		tb.Balance = big.NewInt(0)
+		// a failed token read takes precedence, as in GetTokenBalances
+		tb.Balance, tb.Err = big.NewInt(0), err
 	}
 
-	tb.Symbol, err = token.Symbol(nil)
+	err = pin.call(func(opts *bind.CallOpts) (err error) {
+		tb.Symbol, err = token.Symbol(opts)
+		return err
+	})
 	if err != nil {
 		spl := fmt.Sprintf("Failed to get symbol from contract: %v \n", tb.Contract.String())
 		fmt.Println(spl)
-		tb.Symbol = symbolFix(tb.Contract.String())
+		tb.Symbol = c.registrySymbol(ctx, tb.Contract)
 	}
 
-	tb.Name, err = token.Name(nil)
+	err = pin.call(func(opts *bind.CallOpts) (err error) {
+		tb.Name, err = token.Name(opts)
+		return err
+	})
 	if err != nil {
 		spl := fmt.Sprintf("Failed to retrieve token name from contract: %v | %v\n", tb.Contract.String(), err)
 		fmt.Println(spl)
@@ -16,7 +16,78 @@
 
 package eth
 
+import (
+	"context"
+	"math/big"
+	"testing"
+)
+
 // Verfiy that client implements the Client interface.
 var (
 	_ = Client(&ClientTokenEth{})
 )
+
+func TestGetTokenBalancePinned(t *testing.T) {
+	for _, rejectHashes := range []bool{false, true} {
+		service := newBalanceService(t)
+		service.rejectHashes = rejectHashes
+		c := newTestClient(t, map[string]interface{}{"eth": service})
+
+		tb, err := c.GetTokenBalance(context.Background(), testTokenA, testWalletA, big.NewInt(7))
+		if err != nil {
+			t.Fatal(err)
+		}
+		want := service.headers[7]
+		if tb.Block != 7 || tb.BlockHash != want.Hash() {
+			t.Fatalf("block mismatch: have %d %x", tb.Block, tb.BlockHash)
+		}
+
+		// Decimals, ether balance, balanceOf, symbol and name all read the
+		// same block, by number only when the node rejects hashes.
+		reads := service.readBlocks()
+		if len(reads) != 5 {
+			t.Fatalf("read count mismatch: have %d, want 5", len(reads))
+		}
+		for i, block := range reads {
+			hash, byHash := block.Hash()
+			number, _ := block.Number()
+			switch {
+			case byHash == rejectHashes:
+				t.Fatalf("read %d: by hash %v on a node rejecting hashes %v", i, byHash, rejectHashes)
+			case byHash && hash != want.Hash():
+				t.Fatalf("read %d pinned to hash %x, want %x", i, hash, want.Hash())
+			case !byHash && number != 7:
+				t.Fatalf("read %d at block %d, want 7", i, number)
+			}
+		}
+	}
+}
+
+func TestGetTokenBalanceFailedReads(t *testing.T) {
+	service := newBalanceService(t)
+	c := newTestClient(t, map[string]interface{}{"eth": service})
+
+	// Token B reverts balanceOf for wallet B, reported as a zero balance.
+	tb, err := c.GetTokenBalanceLatest(context.Background(), testTokenB, testWalletB)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if tb.Balance == nil || tb.Balance.Sign() != 0 || tb.Err == nil {
+		t.Fatalf("failed token read: have balance %v, err %v", tb.Balance, tb.Err)
+	}
+	if tb.ETH == nil || tb.ETH.Cmp(service.balances[testWalletB]) != 0 {
+		t.Fatalf("ether balance mismatch: have %v", tb.ETH)
+	}
+
+	// The service knows no ether balance for the token address.
+	tb, err = c.GetTokenBalanceLatest(context.Background(), testTokenA, testTokenA)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if tb.ETH != nil || tb.Err == nil {
+		t.Fatalf("failed ether read: have balance %v, err %v", tb.ETH, tb.Err)
+	}
+	if tb.Balance == nil {
+		t.Fatalf("token balance missing: err %v", tb.Err)
+	}
+}
@@ -1,12 +1,16 @@
-package eth
+//go:build integration
+
+// The tests in this file dial a public node; run them with -tags integration.
+
+package eth_test
 
 import (
-	"testing"
-	"math/big"
 	"context"
 	"fmt"
+	"math/big"
+	"testing"
+
 	mx "github.com/tokenchain/eth-client/eth"
-	"github.com/ethereum/go-ethereum/common/hexutil"
 )
 
 var (
@@ -40,10 +44,12 @@ func ConnectGethRpc() (client_tx *mx.ClientTokenEth) {
 	"v": "0x1b",
 	"r": "0x70b5f34b07c090689297190814533b6f911470496bede67f6ee555b72467b228",
 	"s": "0x39e2149fb6516f13ae48ca6ba256420379efa75ebd83cb52e508992af4f9ad97"
-
- */
+*/
 func TestClientTokenEth_LatestConfirmedTransactionCount(t *testing.T) {
 	geth_rpc = ConnectGethRpc()
+	if geth_rpc == nil {
+		t.Fatal("failed to dial the test node")
+	}
 	tx, _ := geth_rpc.TransactionByBlockNumberIndex(context.Background(), big.NewInt(2470038), big.NewInt(5))
 	if tx != nil {
 		//a:=tx.
@@ -55,8 +61,8 @@ func TestClientTokenEth_LatestConfirmedTransactionCount(t *testing.T) {
 
 		//		log.Info(fmt.Sprintf("Tx Detail: %s", tx.Data.Hash))
 		//log2.New()
-		Amount,_:=hexutil.DecodeBig(tx.Amount)
-		BlockNumber,_:=hexutil.DecodeBig(tx.BlockNumber)
+		Amount, _ := tx.GetETHAmount()
+		BlockNumber, _ := tx.GetBlockNumber()
 		fmt.Println("tx From:", tx.From.String())
 		fmt.Println("tx BlockNumber:", BlockNumber)
 		fmt.Println("tx AccountNonce:", tx.AccountNonce)
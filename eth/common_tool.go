@@ -2,71 +2,47 @@ package eth
 
 import (
 	"math/big"
-	"fmt"
-	"bytes"
+	"errors"
 	"github.com/ethereum/go-ethereum/common"
 )
 
+// mainnetRegistry backs the package level lookups kept for compatibility.
+var mainnetRegistry = DefaultTokenRegistry()
+
+// GetSymbolFromId returns the symbol of a built-in mainnet token.
+//
+// Deprecated: use ClientTokenEth.TokenByAddress or a TokenRegistry.
 func GetSymbolFromId(contract_address common.Address) string {
-	for symbol, address := range token_list {
-		//	fmt.Println("Key:", key, "=>", "Element:", element)
-		if contract_address.String() == address {
-			return symbol
-		}
+	if entry, ok := mainnetRegistry.ByAddress(MainnetChainID, contract_address); ok {
+		return entry.Symbol
 	}
 	return "MISSING"
 }
+
+// GetContractFromSymbol returns the contract of a built-in mainnet token.
+//
+// Deprecated: use ClientTokenEth.TokensBySymbol or a TokenRegistry.
 func GetContractFromSymbol(sym string) common.Address {
-	for symbol, address := range token_list {
-		//	fmt.Println("Key:", key, "=>", "Element:", element)
-		if symbol == sym {
-			return common.HexToAddress(address)
-		}
+	if entries := mainnetRegistry.BySymbol(MainnetChainID, sym); len(entries) > 0 {
+		return entries[0].Address
 	}
 	return common.HexToAddress("")
 }
-func symbolFix(contract string) string {
-	for symbol, address := range token_list {
-		//	fmt.Println("Key:", key, "=>", "Element:", element)
-		if common.HexToAddress(contract).String() == address {
-			return symbol
-		}
-	}
-	return "MISSING"
-}
-
-func bigIntString(balance *big.Int, decimals int64) string {
-	amount := bigIntFloat(balance, decimals)
-	deci := fmt.Sprintf("%%0.%vf", decimals)
-	return clean(fmt.Sprintf(deci, amount))
-}
 
-func bigIntFloat(balance *big.Int, decimals int64) *big.Float {
-	if balance.Sign() == 0 {
-		return big.NewFloat(0)
+// parseTokenAmount converts a human readable amount such as "12.5" into the
+// integer number of base units of a token with the given decimals.
+func parseTokenAmount(amount string, decimals int64) (*big.Int, error) {
+	a, err := ParseAmount(amount, decimals)
+	if err != nil {
+		return nil, err
+	}
+	if a.Sign() < 0 {
+		return nil, errors.New("amount must not be negative")
 	}
-	bal := big.NewFloat(0)
-	bal.SetInt(balance)
-	pow := bigPow(10, decimals)
-	p := big.NewFloat(0)
-	p.SetInt(pow)
-	bal.Quo(bal, p)
-	return bal
+	return a.Int(), nil
 }
 
 func bigPow(a, b int64) *big.Int {
 	r := big.NewInt(a)
 	return r.Exp(r, big.NewInt(b), nil)
 }
-
-func clean(newNum string) string {
-	stringBytes := bytes.TrimRight([]byte(newNum), "0")
-	newNum = string(stringBytes)
-	if stringBytes[len(stringBytes)-1] == 46 {
-		newNum += "0"
-	}
-	if stringBytes[0] == 46 {
-		newNum = "0" + newNum
-	}
-	return newNum
-}
@@ -0,0 +1,33 @@
+package eth
+
+import "testing"
+
+func TestParseTokenAmount(t *testing.T) {
+	tests := []struct {
+		amount   string
+		decimals int64
+		want     string // base units, empty if an error is expected
+	}{
+		{"1", 18, "1000000000000000000"},
+		{"0.5", 6, "500000"},
+		{"12.000001", 6, "12000001"},
+		{"42", 0, "42"},
+		{"0", 18, "0"},
+		{"1.0000001", 6, ""},
+		{"0.5", 0, ""},
+		{"-1", 18, ""},
+		{"1e18", 18, ""},
+		{"", 18, ""},
+	}
+	for _, tt := range tests {
+		got, err := parseTokenAmount(tt.amount, tt.decimals)
+		switch {
+		case tt.want == "" && err == nil:
+			t.Errorf("parseTokenAmount(%q, %d) = %v, want error", tt.amount, tt.decimals, got)
+		case tt.want != "" && err != nil:
+			t.Errorf("parseTokenAmount(%q, %d) failed: %v", tt.amount, tt.decimals, err)
+		case tt.want != "" && got.String() != tt.want:
+			t.Errorf("parseTokenAmount(%q, %d) = %v, want %s", tt.amount, tt.decimals, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,316 @@
+package eth
+
+import (
+	"errors"
+	"fmt"
+	"math/big"
+	"reflect"
+	"strings"
+	"sync"
+
+	"github.com/ethereum/go-ethereum/accounts/abi"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+)
+
+// ErrUnknownEvent is returned when no registered event matches a log.
+var ErrUnknownEvent = errors.New("unknown event")
+
+// erc721EventsABI and erc1155EventsABI hold the events of the NFT standards;
+// the ERC-20 events come from tokenABI.
+const erc721EventsABI = `[
+	{"anonymous":false,"inputs":[{"indexed":true,"name":"from","type":"address"},{"indexed":true,"name":"to","type":"address"},{"indexed":true,"name":"tokenId","type":"uint256"}],"name":"Transfer","type":"event"},
+	{"anonymous":false,"inputs":[{"indexed":true,"name":"owner","type":"address"},{"indexed":true,"name":"approved","type":"address"},{"indexed":true,"name":"tokenId","type":"uint256"}],"name":"Approval","type":"event"},
+	{"anonymous":false,"inputs":[{"indexed":true,"name":"owner","type":"address"},{"indexed":true,"name":"operator","type":"address"},{"indexed":false,"name":"approved","type":"bool"}],"name":"ApprovalForAll","type":"event"}
+]`
+
+const erc1155EventsABI = `[
+	{"anonymous":false,"inputs":[{"indexed":true,"name":"operator","type":"address"},{"indexed":true,"name":"from","type":"address"},{"indexed":true,"name":"to","type":"address"},{"indexed":false,"name":"id","type":"uint256"},{"indexed":false,"name":"value","type":"uint256"}],"name":"TransferSingle","type":"event"},
+	{"anonymous":false,"inputs":[{"indexed":true,"name":"operator","type":"address"},{"indexed":true,"name":"from","type":"address"},{"indexed":true,"name":"to","type":"address"},{"indexed":false,"name":"ids","type":"uint256[]"},{"indexed":false,"name":"values","type":"uint256[]"}],"name":"TransferBatch","type":"event"},
+	{"anonymous":false,"inputs":[{"indexed":false,"name":"value","type":"string"},{"indexed":true,"name":"id","type":"uint256"}],"name":"URI","type":"event"}
+]`
+
+// TransferEvent is an ERC-20 Transfer.
+type TransferEvent struct {
+	From  common.Address
+	To    common.Address
+	Value *big.Int
+}
+
+// ApprovalEvent is an ERC-20 Approval.
+type ApprovalEvent struct {
+	Owner   common.Address
+	Spender common.Address
+	Value   *big.Int
+}
+
+// MintEvent is the Mint event of mintable tokens such as tokenABI.
+type MintEvent struct {
+	To    common.Address
+	Value *big.Int
+}
+
+// NFTTransferEvent is an ERC-721 Transfer.
+type NFTTransferEvent struct {
+	From    common.Address
+	To      common.Address
+	TokenId *big.Int
+}
+
+// NFTApprovalEvent is an ERC-721 Approval.
+type NFTApprovalEvent struct {
+	Owner    common.Address
+	Approved common.Address
+	TokenId  *big.Int
+}
+
+// ApprovalForAllEvent is the ApprovalForAll event of ERC-721 and ERC-1155.
+type ApprovalForAllEvent struct {
+	Owner    common.Address
+	Operator common.Address
+	Approved bool
+}
+
+// TransferSingleEvent is an ERC-1155 TransferSingle.
+type TransferSingleEvent struct {
+	Operator common.Address
+	From     common.Address
+	To       common.Address
+	Id       *big.Int
+	Value    *big.Int
+}
+
+// TransferBatchEvent is an ERC-1155 TransferBatch.
+type TransferBatchEvent struct {
+	Operator common.Address
+	From     common.Address
+	To       common.Address
+	Ids      []*big.Int
+	Values   []*big.Int
+}
+
+// DecodedEvent is a log matched against a registered event.
+type DecodedEvent struct {
+	Name  string
+	Event abi.Event
+	Log   types.Log
+	// Args holds every argument, indexed or not, by its ABI name. Indexed
+	// dynamic arguments (strings, bytes, arrays) are only available as the
+	// hash of their value.
+	Args map[string]interface{}
+	// Value is the typed form of the event, such as *TransferEvent, when one
+	// was registered for it, and nil otherwise.
+	Value interface{}
+}
+
+// eventKey identifies an event by its signature hash and topic count, which
+// tells apart events sharing a signature but not their indexing, such as the
+// ERC-20 and ERC-721 Transfer.
+type eventKey struct {
+	id     common.Hash
+	topics int
+}
+
+type registeredEvent struct {
+	event abi.Event
+	typ   reflect.Type // struct type of DecodedEvent.Value, nil if untyped
+}
+
+// EventRegistry decodes logs by their first topic. It is safe for concurrent
+// use.
+type EventRegistry struct {
+	mu     sync.RWMutex
+	events map[eventKey]registeredEvent
+}
+
+// NewEventRegistry creates an empty registry.
+func NewEventRegistry() *EventRegistry {
+	return &EventRegistry{events: make(map[eventKey]registeredEvent)}
+}
+
+// DefaultEventRegistry creates a registry holding the ERC-20, ERC-721 and
+// ERC-1155 events, along with the Mint, MintFinished, Pause and Unpause events
+// of tokenABI.
+func DefaultEventRegistry() *EventRegistry {
+	r := NewEventRegistry()
+	typed := map[string]interface{}{
+		"Transfer": TransferEvent{},
+		"Approval": ApprovalEvent{},
+		"Mint":     MintEvent{},
+	}
+	if err := r.RegisterABI(tokenABI, typed); err != nil {
+		panic(err)
+	}
+	typed = map[string]interface{}{
+		"TransferSingle": TransferSingleEvent{},
+		"TransferBatch":  TransferBatchEvent{},
+	}
+	if err := r.RegisterABI(erc1155EventsABI, typed); err != nil {
+		panic(err)
+	}
+	// ApprovalForAll is shared with ERC-1155.
+	typed = map[string]interface{}{
+		"Transfer":       NFTTransferEvent{},
+		"Approval":       NFTApprovalEvent{},
+		"ApprovalForAll": ApprovalForAllEvent{},
+	}
+	if err := r.RegisterABI(erc721EventsABI, typed); err != nil {
+		panic(err)
+	}
+	return r
+}
+
+// Register adds event to the registry, replacing any event with the same
+// signature and indexing. If typ is a struct value, decoded logs also carry a
+// pointer to a new one of that type, filled by matching the camel-cased
+// argument names to its fields. Anonymous events have no signature topic and
+// cannot be registered.
+func (r *EventRegistry) Register(event abi.Event, typ interface{}) error {
+	if event.Anonymous {
+		return fmt.Errorf("event %s is anonymous", event.Name)
+	}
+	entry := registeredEvent{event: event}
+	if typ != nil {
+		t := reflect.TypeOf(typ)
+		if t.Kind() == reflect.Ptr {
+			t = t.Elem()
+		}
+		if t.Kind() != reflect.Struct {
+			return fmt.Errorf("event %s: %v is not a struct", event.Name, t)
+		}
+		entry.typ = t
+	}
+	indexed := 0
+	for _, input := range event.Inputs {
+		if input.Indexed {
+			indexed++
+		}
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.events[eventKey{event.ID, 1 + indexed}] = entry
+	return nil
+}
+
+// RegisterABI registers every event of the JSON ABI definition. typed maps
+// event names to the struct types passed to Register.
+func (r *EventRegistry) RegisterABI(definition string, typed map[string]interface{}) error {
+	parsed, err := abi.JSON(strings.NewReader(definition))
+	if err != nil {
+		return err
+	}
+	for name, event := range parsed.Events {
+		if event.Anonymous {
+			continue
+		}
+		if err := r.Register(event, typed[name]); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Lookup returns the event a log with the given topics would decode as.
+func (r *EventRegistry) Lookup(topics []common.Hash) (abi.Event, bool) {
+	if len(topics) == 0 {
+		return abi.Event{}, false
+	}
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	entry, ok := r.events[eventKey{topics[0], len(topics)}]
+	return entry.event, ok
+}
+
+// Decode matches l against the registered events and decodes its arguments.
+// It returns ErrUnknownEvent if none matches.
+func (r *EventRegistry) Decode(l types.Log) (*DecodedEvent, error) {
+	if len(l.Topics) == 0 {
+		return nil, ErrUnknownEvent
+	}
+	r.mu.RLock()
+	entry, ok := r.events[eventKey{l.Topics[0], len(l.Topics)}]
+	r.mu.RUnlock()
+	if !ok {
+		return nil, ErrUnknownEvent
+	}
+
+	args, err := unpackLog(entry.event, l)
+	if err != nil {
+		return nil, err
+	}
+	decoded := &DecodedEvent{
+		Name:  entry.event.Name,
+		Event: entry.event,
+		Log:   l,
+		Args:  args,
+	}
+	if entry.typ != nil {
+		value := reflect.New(entry.typ)
+		if err := copyEventArgs(value.Interface(), entry.event, args); err != nil {
+			return nil, err
+		}
+		decoded.Value = value.Interface()
+	}
+	return decoded, nil
+}
+
+// DecodeInto decodes l into out, a pointer to a struct whose fields are named
+// after the camel-cased arguments of the matching event. Arguments without a
+// matching field are skipped.
+func (r *EventRegistry) DecodeInto(l types.Log, out interface{}) error {
+	event, ok := r.Lookup(l.Topics)
+	if !ok {
+		return ErrUnknownEvent
+	}
+	args, err := unpackLog(event, l)
+	if err != nil {
+		return err
+	}
+	return copyEventArgs(out, event, args)
+}
+
+// unpackLog decodes the topics and data of l as arguments of event.
+func unpackLog(event abi.Event, l types.Log) (map[string]interface{}, error) {
+	var indexed abi.Arguments
+	for _, input := range event.Inputs {
+		if input.Indexed {
+			indexed = append(indexed, input)
+		}
+	}
+	args := make(map[string]interface{})
+	if err := abi.ParseTopicsIntoMap(args, indexed, l.Topics[1:]); err != nil {
+		return nil, fmt.Errorf("event %s: %v", event.Name, err)
+	}
+	if err := event.Inputs.NonIndexed().UnpackIntoMap(args, l.Data); err != nil {
+		return nil, fmt.Errorf("event %s: %v", event.Name, err)
+	}
+	return args, nil
+}
+
+// copyEventArgs sets the fields of the struct out points to from args.
+func copyEventArgs(out interface{}, event abi.Event, args map[string]interface{}) error {
+	dst := reflect.ValueOf(out)
+	if dst.Kind() != reflect.Ptr || dst.Elem().Kind() != reflect.Struct {
+		return fmt.Errorf("event %s: %T is not a struct pointer", event.Name, out)
+	}
+	dst = dst.Elem()
+	for _, input := range event.Inputs {
+		field := dst.FieldByName(abi.ToCamelCase(input.Name))
+		if !field.IsValid() || !field.CanSet() {
+			continue
+		}
+		src := reflect.ValueOf(args[input.Name])
+		switch {
+		case !src.IsValid():
+			continue
+		case src.Type().AssignableTo(field.Type()):
+			field.Set(src)
+		case src.Type().ConvertibleTo(field.Type()):
+			field.Set(src.Convert(field.Type()))
+		default:
+			return fmt.Errorf("event %s: cannot assign %v to field %s of type %v", event.Name, src.Type(), input.Name, field.Type())
+		}
+	}
+	return nil
+}
@@ -0,0 +1,61 @@
+package eth
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/crypto"
+)
+
+func TestEventRegistryDecodeTransfer(t *testing.T) {
+	var (
+		sig  = crypto.Keccak256Hash([]byte("Transfer(address,address,uint256)"))
+		from = common.HexToAddress("0x1000000000000000000000000000000000000001")
+		to   = common.HexToAddress("0x2000000000000000000000000000000000000002")
+		r    = DefaultEventRegistry()
+	)
+
+	erc20 := types.Log{
+		Topics: []common.Hash{sig, common.BytesToHash(from.Bytes()), common.BytesToHash(to.Bytes())},
+		Data:   common.LeftPadBytes(big.NewInt(1000).Bytes(), 32),
+	}
+	decoded, err := r.Decode(erc20)
+	if err != nil {
+		t.Fatal(err)
+	}
+	transfer, ok := decoded.Value.(*TransferEvent)
+	if !ok {
+		t.Fatalf("unexpected value type %T", decoded.Value)
+	}
+	if transfer.From != from || transfer.To != to || transfer.Value.Cmp(big.NewInt(1000)) != 0 {
+		t.Fatalf("unexpected transfer: %+v", transfer)
+	}
+	if decoded.Args["value"].(*big.Int).Cmp(big.NewInt(1000)) != 0 {
+		t.Fatalf("unexpected args: %v", decoded.Args)
+	}
+
+	erc721 := types.Log{
+		Topics: []common.Hash{sig, common.BytesToHash(from.Bytes()), common.BytesToHash(to.Bytes()), common.BigToHash(big.NewInt(7))},
+	}
+	decoded, err = r.Decode(erc721)
+	if err != nil {
+		t.Fatal(err)
+	}
+	nft, ok := decoded.Value.(*NFTTransferEvent)
+	if !ok || nft.TokenId.Int64() != 7 || nft.To != to {
+		t.Fatalf("unexpected NFT transfer: %+v", decoded.Value)
+	}
+
+	var out struct {
+		From  common.Address
+		Value *big.Int
+	}
+	if err := r.DecodeInto(erc20, &out); err != nil || out.From != from || out.Value.Int64() != 1000 {
+		t.Fatalf("unexpected DecodeInto result: %+v, %v", out, err)
+	}
+	if _, err := r.Decode(types.Log{Topics: []common.Hash{{}}}); err != ErrUnknownEvent {
+		t.Fatalf("unexpected error for unknown event: %v", err)
+	}
+}
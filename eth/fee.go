@@ -0,0 +1,239 @@
+package eth
+
+import (
+	"context"
+	"math/big"
+	"sort"
+
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+)
+
+// FeeStrategy describes how the client prices the transactions it builds.
+type FeeStrategy struct {
+	// HistoryBlocks is the number of recent blocks sampled through eth_feeHistory.
+	HistoryBlocks uint64
+	// TipPercentile selects the priority fee among the rewards paid in the
+	// sampled blocks, in the range [0, 100].
+	TipPercentile float64
+	// BaseFeeMultiplier scales the base fee when computing the fee cap, so the
+	// transaction stays includable while the base fee keeps rising.
+	BaseFeeMultiplier int64
+	// CapPercentile, when above zero, selects the base fee the fee cap is
+	// computed from among the base fees of the sampled blocks, in the range
+	// (0, 100]. The next block's base fee is used when it is higher, and when
+	// CapPercentile is zero or no history is available.
+	CapPercentile float64
+	// MinTip is the lowest priority fee that will ever be offered.
+	MinTip *big.Int
+	// MaxFeeCap, when set, bounds the fee cap (and the legacy gas price).
+	MaxFeeCap *big.Int
+	// Legacy forces pre-London pricing even when the chain supports EIP-1559.
+	Legacy bool
+}
+
+// DefaultFeeStrategy returns the strategy used when none has been configured:
+// the median tip of the last 20 blocks and a fee cap of twice the base fee.
+func DefaultFeeStrategy() *FeeStrategy {
+	return &FeeStrategy{
+		HistoryBlocks:     20,
+		TipPercentile:     50,
+		BaseFeeMultiplier: 2,
+		MinTip:            big.NewInt(1000000000), // 1 gwei
+	}
+}
+
+// Fees holds the pricing of a single transaction. GasPrice is set for legacy
+// transactions, GasTipCap and GasFeeCap for EIP-1559 dynamic-fee ones.
+type Fees struct {
+	GasPrice  *big.Int
+	GasTipCap *big.Int
+	GasFeeCap *big.Int
+}
+
+// IsDynamic reports whether the fees describe a type-2 transaction.
+func (f *Fees) IsDynamic() bool {
+	return f.GasFeeCap != nil
+}
+
+// Cost returns the highest price per gas the sender can be charged.
+func (f *Fees) Cost() *big.Int {
+	if f.IsDynamic() {
+		return f.GasFeeCap
+	}
+	return f.GasPrice
+}
+
+// SetFeeStrategy replaces the fee strategy of the client. It should be called
+// before the client is shared between goroutines.
+func (c *ClientTokenEth) SetFeeStrategy(s *FeeStrategy) {
+	c.feeStrategy = s
+}
+
+func (c *ClientTokenEth) feeStrategyOrDefault() *FeeStrategy {
+	if c.feeStrategy == nil {
+		return DefaultFeeStrategy()
+	}
+	return c.feeStrategy
+}
+
+// SuggestFees prices a transaction according to the client's fee strategy. On
+// chains whose latest header carries no baseFeePerGas, like Istanbul and Quorum
+// networks, it falls back to the legacy eth_gasPrice suggestion.
+func (c *ClientTokenEth) SuggestFees(ctx context.Context) (*Fees, error) {
+	s := c.feeStrategyOrDefault()
+
+	head, err := c.HeaderByNumber(ctx, nil)
+	if err != nil {
+		return nil, err
+	}
+	if s.Legacy || head.BaseFee == nil {
+		return c.suggestLegacyFees(ctx, s)
+	}
+
+	var history *ethereum.FeeHistory
+	if s.HistoryBlocks > 0 {
+		history, err = c.FeeHistory(ctx, s.HistoryBlocks, nil, []float64{s.TipPercentile})
+		if err != nil {
+			log.Debug("eth_feeHistory unavailable, falling back to eth_maxPriorityFeePerGas", "err", err)
+			history = nil
+		}
+	}
+	tip := medianReward(history)
+	if tip == nil {
+		if tip, err = c.SuggestGasTipCap(ctx); err != nil {
+			return nil, err
+		}
+	}
+	return dynamicFees(s, head.BaseFee, history, tip), nil
+}
+
+// dynamicFees prices a type-2 transaction from the latest base fee, the fee
+// history sampled by the strategy, if any, and the suggested tip.
+func dynamicFees(s *FeeStrategy, baseFee *big.Int, history *ethereum.FeeHistory, tip *big.Int) *Fees {
+	tip = new(big.Int).Set(tip)
+	if s.MinTip != nil && tip.Cmp(s.MinTip) < 0 {
+		tip.Set(s.MinTip)
+	}
+
+	base := baseFee
+	if history != nil && len(history.BaseFee) > 0 {
+		// The last entry is the base fee of the next block.
+		if next := history.BaseFee[len(history.BaseFee)-1]; next != nil && next.Cmp(base) > 0 {
+			base = next
+		}
+		if s.CapPercentile > 0 {
+			if p := percentile(history.BaseFee, s.CapPercentile); p != nil && p.Cmp(base) > 0 {
+				base = p
+			}
+		}
+	}
+	multiplier := s.BaseFeeMultiplier
+	if multiplier < 1 {
+		multiplier = 1
+	}
+	feeCap := new(big.Int).Mul(base, big.NewInt(multiplier))
+	feeCap.Add(feeCap, tip)
+	if s.MaxFeeCap != nil && feeCap.Cmp(s.MaxFeeCap) > 0 {
+		feeCap = new(big.Int).Set(s.MaxFeeCap)
+		if tip.Cmp(feeCap) > 0 {
+			tip.Set(feeCap)
+		}
+	}
+	return &Fees{GasTipCap: tip, GasFeeCap: feeCap}
+}
+
+// percentile returns the p-th percentile of values, ignoring nil entries.
+func percentile(values []*big.Int, p float64) *big.Int {
+	var sorted []*big.Int
+	for _, v := range values {
+		if v != nil {
+			sorted = append(sorted, v)
+		}
+	}
+	if len(sorted) == 0 {
+		return nil
+	}
+	sort.Slice(sorted, func(i, j int) bool { return sorted[i].Cmp(sorted[j]) < 0 })
+	if p > 100 {
+		p = 100
+	}
+	i := int(float64(len(sorted)-1) * p / 100)
+	return new(big.Int).Set(sorted[i])
+}
+
+func (c *ClientTokenEth) suggestLegacyFees(ctx context.Context, s *FeeStrategy) (*Fees, error) {
+	gasPrice, err := c.SuggestGasPrice(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if s.MaxFeeCap != nil && gasPrice.Cmp(s.MaxFeeCap) > 0 {
+		gasPrice = new(big.Int).Set(s.MaxFeeCap)
+	}
+	return &Fees{GasPrice: gasPrice}, nil
+}
+
+// medianReward returns the median across blocks of the single percentile
+// requested from eth_feeHistory, ignoring empty blocks.
+func medianReward(history *ethereum.FeeHistory) *big.Int {
+	if history == nil {
+		return nil
+	}
+	var rewards []*big.Int
+	for i, r := range history.Reward {
+		if len(r) == 0 || r[0] == nil {
+			continue
+		}
+		if i < len(history.GasUsedRatio) && history.GasUsedRatio[i] == 0 {
+			continue
+		}
+		rewards = append(rewards, r[0])
+	}
+	if len(rewards) == 0 {
+		return nil
+	}
+	sort.Slice(rewards, func(i, j int) bool { return rewards[i].Cmp(rewards[j]) < 0 })
+	return new(big.Int).Set(rewards[len(rewards)/2])
+}
+
+// estimateGas estimates the gas limit of a call priced with the given fees.
+func (c *ClientTokenEth) estimateGas(ctx context.Context, from common.Address, to *common.Address, value *big.Int, data []byte, fees *Fees) (uint64, error) {
+	msg := ethereum.CallMsg{
+		From:  from,
+		To:    to,
+		Value: value,
+		Data:  data,
+	}
+	if fees.IsDynamic() {
+		msg.GasTipCap, msg.GasFeeCap = fees.GasTipCap, fees.GasFeeCap
+	} else {
+		msg.GasPrice = fees.GasPrice
+	}
+	return c.EstimateGas(ctx, msg)
+}
+
+// newTransaction builds an unsigned transaction, dynamic-fee or legacy
+// depending on the fees it is priced with.
+func newTransaction(chainID *big.Int, nonce uint64, to *common.Address, value *big.Int, gas uint64, fees *Fees, data []byte) *types.Transaction {
+	if fees.IsDynamic() {
+		return types.NewTx(&types.DynamicFeeTx{
+			ChainID:   chainID,
+			Nonce:     nonce,
+			GasTipCap: fees.GasTipCap,
+			GasFeeCap: fees.GasFeeCap,
+			Gas:       gas,
+			To:        to,
+			Value:     value,
+			Data:      data,
+		})
+	}
+	return types.NewTx(&types.LegacyTx{
+		Nonce:    nonce,
+		GasPrice: fees.GasPrice,
+		Gas:      gas,
+		To:       to,
+		Value:    value,
+		Data:     data,
+	})
+}
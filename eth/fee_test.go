@@ -0,0 +1,152 @@
+package eth
+
+import (
+	"context"
+	"errors"
+	"math/big"
+	"testing"
+
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/core/types"
+)
+
+const gwei = 1000000000
+
+// feeService serves the eth methods SuggestFees relies on.
+type feeService struct {
+	head    *types.Header
+	rewards []int64 // one reward per block; nil fails eth_feeHistory
+	bases   []int64 // base fees, one more than rewards
+	tip     int64
+	price   int64
+}
+
+func (s *feeService) GetBlockByNumber(number string, full bool) (*types.Header, error) {
+	return s.head, nil
+}
+
+func (s *feeService) FeeHistory(count hexutil.Uint64, last string, percentiles []float64) (map[string]interface{}, error) {
+	if s.rewards == nil {
+		return nil, errors.New("the method eth_feeHistory does not exist/is not available")
+	}
+	var (
+		rewards [][]*hexutil.Big
+		bases   []*hexutil.Big
+		ratios  []float64
+	)
+	for _, r := range s.rewards {
+		rewards = append(rewards, []*hexutil.Big{(*hexutil.Big)(big.NewInt(r))})
+		ratios = append(ratios, 0.5)
+	}
+	for _, b := range s.bases {
+		bases = append(bases, (*hexutil.Big)(big.NewInt(b)))
+	}
+	return map[string]interface{}{
+		"oldestBlock":   (*hexutil.Big)(big.NewInt(1)),
+		"reward":        rewards,
+		"baseFeePerGas": bases,
+		"gasUsedRatio":  ratios,
+	}, nil
+}
+
+func (s *feeService) MaxPriorityFeePerGas() (*hexutil.Big, error) {
+	return (*hexutil.Big)(big.NewInt(s.tip)), nil
+}
+
+func (s *feeService) GasPrice() (*hexutil.Big, error) {
+	return (*hexutil.Big)(big.NewInt(s.price)), nil
+}
+
+func TestMedianReward(t *testing.T) {
+	tests := []struct {
+		rewards []int64
+		ratios  []float64
+		want    int64 // -1 for nil
+	}{
+		{nil, nil, -1},
+		{[]int64{3, 1, 2}, []float64{0.5, 0.5, 0.5}, 2},
+		{[]int64{3, 1, 2, 4}, []float64{0.5, 0.5, 0.5, 0.5}, 3},
+		// Empty blocks report a zero reward and are skipped.
+		{[]int64{0, 0, 5}, []float64{0, 0, 0.9}, 5},
+		{[]int64{0, 0}, []float64{0, 0}, -1},
+	}
+	for i, test := range tests {
+		history := &ethereum.FeeHistory{GasUsedRatio: test.ratios}
+		for _, r := range test.rewards {
+			history.Reward = append(history.Reward, []*big.Int{big.NewInt(r)})
+		}
+		have := medianReward(history)
+		switch {
+		case test.want < 0 && have != nil:
+			t.Errorf("test %d: have %v, want nil", i, have)
+		case test.want >= 0 && (have == nil || have.Int64() != test.want):
+			t.Errorf("test %d: have %v, want %d", i, have, test.want)
+		}
+	}
+}
+
+func TestSuggestFees(t *testing.T) {
+	london := testHeader(100, nil)
+	london.BaseFee = big.NewInt(10 * gwei)
+
+	tests := []struct {
+		name     string
+		strategy *FeeStrategy
+		service  *feeService
+		want     Fees
+	}{
+		{
+			name:     "median tip, cap from next base fee",
+			strategy: DefaultFeeStrategy(),
+			service: &feeService{
+				head:    london,
+				rewards: []int64{1 * gwei, 3 * gwei, 2 * gwei},
+				bases:   []int64{9 * gwei, 10 * gwei, 10 * gwei, 11 * gwei},
+			},
+			want: Fees{GasTipCap: big.NewInt(2 * gwei), GasFeeCap: big.NewInt(24 * gwei)},
+		},
+		{
+			name:     "cap percentile above next base fee",
+			strategy: &FeeStrategy{HistoryBlocks: 3, TipPercentile: 50, BaseFeeMultiplier: 1, CapPercentile: 100},
+			service: &feeService{
+				head:    london,
+				rewards: []int64{2 * gwei, 2 * gwei, 2 * gwei},
+				bases:   []int64{30 * gwei, 10 * gwei, 10 * gwei, 10 * gwei},
+			},
+			want: Fees{GasTipCap: big.NewInt(2 * gwei), GasFeeCap: big.NewInt(32 * gwei)},
+		},
+		{
+			name:     "no fee history, tip floored and cap bounded",
+			strategy: &FeeStrategy{HistoryBlocks: 20, BaseFeeMultiplier: 2, MinTip: big.NewInt(gwei), MaxFeeCap: big.NewInt(15 * gwei)},
+			service:  &feeService{head: london, tip: gwei / 2},
+			want:     Fees{GasTipCap: big.NewInt(gwei), GasFeeCap: big.NewInt(15 * gwei)},
+		},
+		{
+			name:     "pre-London chain",
+			strategy: DefaultFeeStrategy(),
+			service:  &feeService{head: testHeader(100, nil), price: 5 * gwei},
+			want:     Fees{GasPrice: big.NewInt(5 * gwei)},
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			c := newTestClient(t, map[string]interface{}{"eth": test.service})
+			c.SetFeeStrategy(test.strategy)
+
+			have, err := c.SuggestFees(context.Background())
+			if err != nil {
+				t.Fatal(err)
+			}
+			for _, pair := range [][2]*big.Int{
+				{have.GasPrice, test.want.GasPrice},
+				{have.GasTipCap, test.want.GasTipCap},
+				{have.GasFeeCap, test.want.GasFeeCap},
+			} {
+				if (pair[0] == nil) != (pair[1] == nil) || (pair[0] != nil && pair[0].Cmp(pair[1]) != 0) {
+					t.Fatalf("fees mismatch: have %+v, want %+v", have, test.want)
+				}
+			}
+		})
+	}
+}
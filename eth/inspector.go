@@ -0,0 +1,189 @@
+package eth
+
+import (
+	"context"
+	"math/big"
+
+	"github.com/ethereum/go-ethereum/accounts/abi/bind"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+)
+
+// TokenInfo is a snapshot of the public state of an ERC-20 contract. Owner,
+// Paused and MintingFinished are nil when the contract does not implement the
+// corresponding Ownable, Pausable or Mintable method.
+type TokenInfo struct {
+	Contract        common.Address
+	Name            string
+	Symbol          string
+	Decimals        int64
+	TotalSupply     *big.Int
+	Owner           *common.Address
+	Paused          *bool
+	MintingFinished *bool
+	// Block is the number of the block the snapshot was read at. It is nil if
+	// the latest block was read through a caller unable to fetch headers.
+	Block *big.Int
+}
+
+// headerReader is implemented by callers, such as the client, that can pin
+// a series of reads to the latest block.
+type headerReader interface {
+	HeaderByNumber(ctx context.Context, number *big.Int) (*types.Header, error)
+}
+
+// TokenInspector exposes the read-only methods of an ERC-20 contract, each
+// evaluated at a given block; a nil block means the latest one.
+type TokenInspector struct {
+	contract common.Address
+	caller   *tokenCaller
+	headers  headerReader // nil if the caller cannot fetch headers
+}
+
+// NewTokenInspector creates an inspector for the token at contract, reading
+// through caller.
+func NewTokenInspector(contract common.Address, caller bind.ContractCaller) (*TokenInspector, error) {
+	tc, err := newTokenCaller(contract, caller)
+	if err != nil {
+		return nil, err
+	}
+	headers, _ := caller.(headerReader)
+	return &TokenInspector{contract: contract, caller: tc, headers: headers}, nil
+}
+
+// TokenInspector creates an inspector for the token at contract.
+func (c *ClientTokenEth) TokenInspector(contract common.Address) (*TokenInspector, error) {
+	return NewTokenInspector(contract, c)
+}
+
+// Contract returns the address of the inspected token.
+func (t *TokenInspector) Contract() common.Address {
+	return t.contract
+}
+
+func callOpts(ctx context.Context, block *big.Int) *bind.CallOpts {
+	return &bind.CallOpts{Context: ctx, BlockNumber: block}
+}
+
+// Name returns the token name.
+func (t *TokenInspector) Name(ctx context.Context, block *big.Int) (string, error) {
+	return t.caller.Name(callOpts(ctx, block))
+}
+
+// Symbol returns the token symbol.
+func (t *TokenInspector) Symbol(ctx context.Context, block *big.Int) (string, error) {
+	return t.caller.Symbol(callOpts(ctx, block))
+}
+
+// Decimals returns the number of decimals of the token.
+func (t *TokenInspector) Decimals(ctx context.Context, block *big.Int) (int64, error) {
+	d, err := t.caller.Decimals(callOpts(ctx, block))
+	if err != nil {
+		return 0, err
+	}
+	return d.Int64(), nil
+}
+
+// TotalSupply returns the total supply of the token in base units.
+func (t *TokenInspector) TotalSupply(ctx context.Context, block *big.Int) (*big.Int, error) {
+	return t.caller.TotalSupply(callOpts(ctx, block))
+}
+
+// BalanceOf returns the token balance of owner in base units.
+func (t *TokenInspector) BalanceOf(ctx context.Context, owner common.Address, block *big.Int) (*big.Int, error) {
+	return t.caller.BalanceOf(callOpts(ctx, block), owner)
+}
+
+// Allowance returns how many base units spender may still transfer from owner.
+func (t *TokenInspector) Allowance(ctx context.Context, owner, spender common.Address, block *big.Int) (*big.Int, error) {
+	return t.caller.Allowance(callOpts(ctx, block), owner, spender)
+}
+
+// Owner returns the owner of an Ownable token.
+func (t *TokenInspector) Owner(ctx context.Context, block *big.Int) (common.Address, error) {
+	return t.caller.Owner(callOpts(ctx, block))
+}
+
+// Paused reports whether a Pausable token is paused.
+func (t *TokenInspector) Paused(ctx context.Context, block *big.Int) (bool, error) {
+	return t.caller.Paused(callOpts(ctx, block))
+}
+
+// MintingFinished reports whether minting of a Mintable token is finished.
+func (t *TokenInspector) MintingFinished(ctx context.Context, block *big.Int) (bool, error) {
+	return t.caller.MintingFinished(callOpts(ctx, block))
+}
+
+// Info collects the token metadata, supply and optional ownership, pause and
+// minting state at block. Failing optional calls are left nil. With a nil
+// block, every call is pinned to the latest header, so that the snapshot is
+// consistent even if new blocks arrive in between.
+func (t *TokenInspector) Info(ctx context.Context, block *big.Int) (*TokenInfo, error) {
+	read := func(fn func(opts *bind.CallOpts) error) error {
+		return fn(callOpts(ctx, block))
+	}
+	if block == nil && t.headers != nil {
+		header, err := t.headers.HeaderByNumber(ctx, nil)
+		if err != nil {
+			return nil, err
+		}
+		block = header.Number
+		read = newBlockPin(ctx, header).call
+	}
+	info := &TokenInfo{Contract: t.contract, Block: block}
+
+	err := read(func(opts *bind.CallOpts) (err error) {
+		info.Name, err = t.caller.Name(opts)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+	err = read(func(opts *bind.CallOpts) (err error) {
+		info.Symbol, err = t.caller.Symbol(opts)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+	err = read(func(opts *bind.CallOpts) error {
+		decimals, err := t.caller.Decimals(opts)
+		if err == nil {
+			info.Decimals = decimals.Int64()
+		}
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+	err = read(func(opts *bind.CallOpts) (err error) {
+		info.TotalSupply, err = t.caller.TotalSupply(opts)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	read(func(opts *bind.CallOpts) error {
+		owner, err := t.caller.Owner(opts)
+		if err == nil {
+			info.Owner = &owner
+		}
+		return err
+	})
+	read(func(opts *bind.CallOpts) error {
+		paused, err := t.caller.Paused(opts)
+		if err == nil {
+			info.Paused = &paused
+		}
+		return err
+	})
+	read(func(opts *bind.CallOpts) error {
+		finished, err := t.caller.MintingFinished(opts)
+		if err == nil {
+			info.MintingFinished = &finished
+		}
+		return err
+	})
+	return info, nil
+}
@@ -0,0 +1,88 @@
+package eth
+
+import (
+	"context"
+	"math/big"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+)
+
+func TestTokenInspectorInfo(t *testing.T) {
+	var (
+		contract = common.HexToAddress("0x86fa049857e0209aa7d9e616f7eb3b3b78ecfdb0")
+		owner    = common.HexToAddress("0x3fd3adba69955f85bc34860b77a64c2c52c981ea")
+		headers  = testChain(10)
+		supply   = big.NewInt(1000000)
+	)
+	service := &chainService{headers: headers}
+	service.call = tokenCalls(t, func(to common.Address, method string, args []interface{}) (interface{}, error) {
+		switch method {
+		case "name":
+			return "Token", nil
+		case "symbol":
+			return "TKN", nil
+		case "decimals":
+			return big.NewInt(6), nil
+		case "totalSupply":
+			return supply, nil
+		case "owner":
+			return owner, nil
+		case "paused":
+			return true, nil
+		}
+		return nil, errReverted // not Mintable
+	})
+	c := newTestClient(t, map[string]interface{}{"eth": service})
+	inspector, err := c.TokenInspector(contract)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	check := func(info *TokenInfo, block int64) {
+		t.Helper()
+		if info.Name != "Token" || info.Symbol != "TKN" || info.Decimals != 6 || info.TotalSupply.Cmp(supply) != 0 {
+			t.Fatalf("metadata mismatch: %+v", info)
+		}
+		if info.Owner == nil || *info.Owner != owner {
+			t.Fatalf("owner mismatch: have %v, want %v", info.Owner, owner)
+		}
+		if info.Paused == nil || !*info.Paused {
+			t.Fatalf("paused mismatch: have %v", info.Paused)
+		}
+		if info.MintingFinished != nil {
+			t.Fatalf("minting finished set for a non-mintable token: %v", *info.MintingFinished)
+		}
+		if info.Block == nil || info.Block.Int64() != block {
+			t.Fatalf("block mismatch: have %v, want %d", info.Block, block)
+		}
+	}
+
+	// The latest block is pinned by hash for every call. Failing calls are
+	// retried by number of the same block to tell whether hashes are rejected.
+	info, err := inspector.Info(context.Background(), nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	check(info, 9)
+	for _, block := range service.readBlocks() {
+		if hash, ok := block.Hash(); ok && hash != headers[9].Hash() {
+			t.Fatalf("call pinned to hash %x, want head %x", hash, headers[9].Hash())
+		}
+		if number, ok := block.Number(); ok && number != 9 {
+			t.Fatalf("call made at block %d, want head 9", number)
+		}
+	}
+
+	// An explicit block is read by number.
+	info, err = inspector.Info(context.Background(), big.NewInt(4))
+	if err != nil {
+		t.Fatal(err)
+	}
+	check(info, 4)
+	for _, block := range service.readBlocks() {
+		if number, ok := block.Number(); !ok || number != 4 {
+			t.Fatalf("call not made at block 4: %v", block)
+		}
+	}
+}
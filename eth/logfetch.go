@@ -0,0 +1,300 @@
+package eth
+
+import (
+	"context"
+	"math/big"
+	"strings"
+	"sync"
+
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/core/types"
+)
+
+// LogFetchConfig tunes FilterLogsChunked.
+type LogFetchConfig struct {
+	// InitialChunk is the number of blocks of the first eth_getLogs queries.
+	InitialChunk uint64
+	// MinChunk and MaxChunk bound the adaptive chunk size.
+	MinChunk uint64
+	MaxChunk uint64
+	// Concurrency is the number of chunks fetched, or buffered while waiting
+	// for earlier chunks to be consumed, at once.
+	Concurrency int
+}
+
+// DefaultLogFetchConfig returns settings suitable for public providers.
+func DefaultLogFetchConfig() LogFetchConfig {
+	return LogFetchConfig{
+		InitialChunk: 2000,
+		MinChunk:     1,
+		MaxChunk:     100000,
+		Concurrency:  4,
+	}
+}
+
+// isLogLimitError reports whether err is a provider refusing a log query for
+// its size, as opposed to a malformed query.
+func isLogLimitError(err error) bool {
+	msg := strings.ToLower(err.Error())
+	for _, s := range []string{
+		"more than", // "query returned more than 10000 results"
+		"limit exceeded",
+		"range too large",
+		"block range",
+		"response size",
+		"too many",
+		"timeout",
+		"timed out",
+		"deadline exceeded",
+	} {
+		if strings.Contains(msg, s) {
+			return true
+		}
+	}
+	return false
+}
+
+// logFetcher tracks the adaptive chunk size shared by the chunk workers.
+type logFetcher struct {
+	client *ClientTokenEth
+	query  ethereum.FilterQuery
+	config LogFetchConfig
+
+	mu    sync.Mutex
+	chunk uint64
+}
+
+func (f *logFetcher) chunkSize() uint64 {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return f.chunk
+}
+
+func (f *logFetcher) grow() {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if f.chunk *= 2; f.chunk > f.config.MaxChunk {
+		f.chunk = f.config.MaxChunk
+	}
+}
+
+func (f *logFetcher) shrink(failed uint64) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if half := failed / 2; half < f.chunk {
+		f.chunk = half
+	}
+	if f.chunk < f.config.MinChunk {
+		f.chunk = f.config.MinChunk
+	}
+}
+
+// fetch returns the logs of blocks [from, to], halving the range for as long
+// as the provider refuses it.
+func (f *logFetcher) fetch(ctx context.Context, from, to uint64) ([]types.Log, error) {
+	q := f.query
+	q.FromBlock, q.ToBlock = new(big.Int).SetUint64(from), new(big.Int).SetUint64(to)
+	logs, err := f.client.FilterLogs(ctx, q)
+	if err == nil {
+		f.grow()
+		return logs, nil
+	}
+	if from == to || !isLogLimitError(err) || ctx.Err() != nil {
+		return nil, err
+	}
+	f.shrink(to - from + 1)
+	log.Debug("Splitting log query", "from", from, "to", to, "err", err)
+
+	mid := from + (to-from)/2
+	left, err := f.fetch(ctx, from, mid)
+	if err != nil {
+		return nil, err
+	}
+	right, err := f.fetch(ctx, mid+1, to)
+	if err != nil {
+		return nil, err
+	}
+	return append(left, right...), nil
+}
+
+// LogIterator streams the results of FilterLogsChunked in block order.
+type LogIterator struct {
+	batches chan []types.Log
+	cancel  context.CancelFunc
+
+	mu  sync.Mutex
+	err error
+
+	batch []types.Log
+	cur   types.Log
+}
+
+// FilterLogsChunked runs q over its block range in chunks, sized adaptively:
+// a chunk the provider refuses for its size is halved, and the chunk size
+// grows again after successes. Up to config.Concurrency chunks are fetched in
+// parallel, but logs are produced in order and at most that many chunks are
+// held in memory. A nil q.ToBlock is resolved to the current head.
+func (c *ClientTokenEth) FilterLogsChunked(ctx context.Context, q ethereum.FilterQuery, config LogFetchConfig) *LogIterator {
+	def := DefaultLogFetchConfig()
+	if config.InitialChunk == 0 {
+		config.InitialChunk = def.InitialChunk
+	}
+	if config.MinChunk == 0 {
+		config.MinChunk = def.MinChunk
+	}
+	if config.MaxChunk < config.MinChunk {
+		config.MaxChunk = def.MaxChunk
+	}
+	if config.Concurrency < 1 {
+		config.Concurrency = def.Concurrency
+	}
+
+	ctx, cancel := context.WithCancel(ctx)
+	it := &LogIterator{
+		batches: make(chan []types.Log),
+		cancel:  cancel,
+	}
+	f := &logFetcher{client: c, query: q, config: config, chunk: config.InitialChunk}
+	go it.run(ctx, f)
+	return it
+}
+
+func (it *LogIterator) fail(err error) {
+	it.mu.Lock()
+	defer it.mu.Unlock()
+	if it.err == nil {
+		it.err = err
+	}
+}
+
+func (it *LogIterator) run(ctx context.Context, f *logFetcher) {
+	defer it.cancel()
+	defer close(it.batches)
+
+	emit := func(logs []types.Log) bool {
+		if len(logs) == 0 {
+			return true
+		}
+		select {
+		case it.batches <- logs:
+			return true
+		case <-ctx.Done():
+			it.fail(ctx.Err())
+			return false
+		}
+	}
+	if f.query.BlockHash != nil {
+		logs, err := f.client.FilterLogs(ctx, f.query)
+		if err != nil {
+			it.fail(err)
+			return
+		}
+		emit(logs)
+		return
+	}
+
+	var from, to uint64
+	if f.query.FromBlock != nil {
+		from = f.query.FromBlock.Uint64()
+	}
+	if f.query.ToBlock != nil {
+		to = f.query.ToBlock.Uint64()
+	} else {
+		head, err := f.client.BlockNumber(ctx)
+		if err != nil {
+			it.fail(err)
+			return
+		}
+		to = head.Uint64()
+	}
+
+	type result struct {
+		start, end uint64
+		logs       []types.Log
+		err        error
+	}
+	var (
+		results     = make(chan result, f.config.Concurrency)
+		pending     = make(map[uint64]result)
+		next        = from // first block not dispatched yet
+		expected    = from // first block not emitted yet
+		outstanding = 0    // chunks dispatched but not emitted
+	)
+	for expected <= to {
+		for next <= to && outstanding < f.config.Concurrency {
+			start, end := next, next+f.chunkSize()-1
+			if end > to || end < start {
+				end = to
+			}
+			go func() {
+				logs, err := f.fetch(ctx, start, end)
+				results <- result{start, end, logs, err}
+			}()
+			outstanding++
+			if next = end + 1; next == 0 {
+				break // wrapped around the last block
+			}
+		}
+
+		var r result
+		select {
+		case r = <-results:
+		case <-ctx.Done():
+			it.fail(ctx.Err())
+			return
+		}
+		if r.err != nil {
+			it.fail(r.err)
+			return
+		}
+		pending[r.start] = r
+		for {
+			r, ok := pending[expected]
+			if !ok {
+				break
+			}
+			delete(pending, expected)
+			if !emit(r.logs) {
+				return
+			}
+			outstanding--
+			if expected = r.end + 1; expected == 0 {
+				return
+			}
+		}
+	}
+}
+
+// Next advances to the next log, returning false at the end of the range or
+// on error.
+func (it *LogIterator) Next() bool {
+	for len(it.batch) == 0 {
+		batch, ok := <-it.batches
+		if !ok {
+			return false
+		}
+		it.batch = batch
+	}
+	it.cur, it.batch = it.batch[0], it.batch[1:]
+	return true
+}
+
+// Log returns the current log.
+func (it *LogIterator) Log() types.Log {
+	return it.cur
+}
+
+// Err returns the error that stopped the iteration, if any.
+func (it *LogIterator) Err() error {
+	it.mu.Lock()
+	defer it.mu.Unlock()
+	return it.err
+}
+
+// Close stops the fetch. It must be called if the iteration is abandoned
+// before Next returns false.
+func (it *LogIterator) Close() {
+	it.cancel()
+	for range it.batches {
+	}
+}
@@ -0,0 +1,58 @@
+package eth
+
+import (
+	"context"
+	"math/big"
+	"testing"
+
+	"github.com/ethereum/go-ethereum"
+)
+
+func TestFilterLogsChunkedSplitting(t *testing.T) {
+	service := &logService{chainService: &chainService{headers: testChain(40)}, limit: 3}
+	for i := 0; i < 40; i++ {
+		service.addLog(i, 0)
+		service.addLog(i, 1)
+	}
+	c := newTestClient(t, map[string]interface{}{"eth": service})
+
+	q := ethereum.FilterQuery{FromBlock: big.NewInt(0), ToBlock: big.NewInt(39)}
+	it := c.FilterLogsChunked(context.Background(), q, LogFetchConfig{InitialChunk: 16, Concurrency: 2})
+	var n int
+	for it.Next() {
+		l := it.Log()
+		if l.BlockNumber != uint64(n/2) || l.Index != uint(n%2) {
+			t.Fatalf("log %d out of order: block %d index %d", n, l.BlockNumber, l.Index)
+		}
+		n++
+	}
+	if err := it.Err(); err != nil {
+		t.Fatal(err)
+	}
+	if n != 80 {
+		t.Fatalf("log count mismatch: have %d, want 80", n)
+	}
+	var refused int
+	for _, r := range service.queried() {
+		if r[1]-r[0]+1 > service.limit {
+			refused++
+		}
+	}
+	if refused == 0 {
+		t.Error("no query was split")
+	}
+}
+
+func TestFilterLogsChunkedError(t *testing.T) {
+	// A single block over the limit cannot be split any further.
+	service := &logService{chainService: &chainService{headers: testChain(10)}, limit: -1}
+	c := newTestClient(t, map[string]interface{}{"eth": service})
+
+	q := ethereum.FilterQuery{FromBlock: big.NewInt(0), ToBlock: big.NewInt(9)}
+	it := c.FilterLogsChunked(context.Background(), q, LogFetchConfig{InitialChunk: 4})
+	for it.Next() {
+	}
+	if err := it.Err(); err == nil || err.Error() != errLogLimit.Error() {
+		t.Fatalf("error mismatch: have %v, want %v", err, errLogLimit)
+	}
+}
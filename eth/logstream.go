@@ -0,0 +1,313 @@
+package eth
+
+import (
+	"context"
+	"errors"
+	"math/big"
+	"time"
+
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	ethrpc "github.com/ethereum/go-ethereum/rpc"
+)
+
+const (
+	// logRetention is how many blocks below the stream position delivered
+	// logs are remembered for de-duplication and reorg detection, and how far
+	// below it every backfill starts.
+	logRetention = 128
+	minBackoff   = time.Second
+	maxBackoff   = time.Minute
+)
+
+// logKey identifies a log across redeliveries.
+type logKey struct {
+	block common.Hash
+	index uint
+}
+
+// LogStream delivers the logs matching a filter without gaps or duplicates
+// across connection losses. Over websocket it follows eth_subscribe("logs")
+// and, after every reconnect, backfills the blocks missed in between with
+// eth_getLogs; on HTTP-only endpoints it polls eth_getFilterChanges instead.
+// Logs reorganised out of the chain are delivered again with Removed set.
+type LogStream struct {
+	client *ClientTokenEth
+	query  ethereum.FilterQuery
+
+	// PollInterval is the eth_getFilterChanges polling period.
+	PollInterval time.Duration
+	// FetchConfig tunes the chunked eth_getLogs queries of the backfills.
+	FetchConfig LogFetchConfig
+	// Checkpoints, when set, persists under Name the last block whose logs
+	// were all delivered, and Run resumes after it. Logs of the blocks that
+	// follow may be delivered again after a restart.
+	Checkpoints CheckpointStore
+	Name        string
+
+	fromHead bool   // next is still to be set to the current head
+	start    uint64 // lowest block whose logs are delivered
+	next     uint64 // lowest block whose logs may still be missing
+	seen     map[logKey]types.Log
+}
+
+// NewLogStream creates a stream of the logs matching q from q.FromBlock, or
+// from the current head when it is nil. q.ToBlock is ignored.
+func (c *ClientTokenEth) NewLogStream(q ethereum.FilterQuery) *LogStream {
+	s := &LogStream{
+		client:       c,
+		query:        q,
+		PollInterval: defaultPollInterval,
+		fromHead:     q.FromBlock == nil,
+		seen:         make(map[logKey]types.Log),
+	}
+	if q.FromBlock != nil {
+		s.start = q.FromBlock.Uint64()
+		s.next = s.start
+	}
+	s.query.FromBlock, s.query.ToBlock, s.query.BlockHash = nil, nil, nil
+	return s
+}
+
+// Run delivers logs to ch until ctx is cancelled, reconnecting with
+// exponential backoff whenever the subscription or the node fails. A saved
+// checkpoint takes precedence over the start block of the query;
+// ErrCheckpointNotCanonical is returned if its block was reorganised away.
+func (s *LogStream) Run(ctx context.Context, ch chan<- types.Log) error {
+	if err := s.restore(ctx); err != nil {
+		return err
+	}
+	if s.fromHead {
+		head, err := s.client.BlockNumber(ctx)
+		if err != nil {
+			return err
+		}
+		s.start, s.next, s.fromHead = head.Uint64(), head.Uint64(), false
+	}
+	backoff := minBackoff
+	for {
+		start := time.Now()
+		err := s.subscribe(ctx, ch)
+		if err == ethrpc.ErrNotificationsUnsupported {
+			err = s.poll(ctx, ch)
+		}
+		if ctx.Err() != nil {
+			return ctx.Err()
+		}
+		if time.Since(start) > maxBackoff {
+			backoff = minBackoff
+		}
+		log.Warn("Log stream interrupted, reconnecting", "next", s.next, "backoff", backoff, "err", err)
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-time.After(backoff):
+		}
+		if backoff *= 2; backoff > maxBackoff {
+			backoff = maxBackoff
+		}
+	}
+}
+
+// subscribe follows the live subscription until it fails. The subscription is
+// opened before the backfill so no log falls between the two.
+func (s *LogStream) subscribe(ctx context.Context, ch chan<- types.Log) error {
+	live := make(chan types.Log, 128)
+	sub, err := s.client.SubscribeFilterLogs(ctx, s.query, live)
+	if err != nil {
+		return err
+	}
+	defer sub.Unsubscribe()
+
+	if err := s.backfill(ctx, ch); err != nil {
+		return err
+	}
+	for {
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case err := <-sub.Err():
+			if err == nil {
+				err = errors.New("subscription closed")
+			}
+			return err
+		case l := <-live:
+			if err := s.deliver(ctx, ch, l); err != nil {
+				return err
+			}
+		}
+	}
+}
+
+// backfill fetches the logs from logRetention blocks below the stream
+// position up to the current head, and reports previously delivered logs of
+// that range that are gone. Starting below the position catches blocks
+// reorganised while disconnected; logs delivered before are dropped as
+// duplicates.
+func (s *LogStream) backfill(ctx context.Context, ch chan<- types.Log) error {
+	header, err := s.client.HeaderByNumber(ctx, nil)
+	if err != nil {
+		return err
+	}
+	head := header.Number
+	if head.Uint64() < s.next {
+		return nil
+	}
+	from := s.start
+	if s.next > from+logRetention {
+		from = s.next - logRetention
+	}
+	q := s.query
+	q.FromBlock, q.ToBlock = new(big.Int).SetUint64(from), head
+	var (
+		logs []types.Log
+		it   = s.client.FilterLogsChunked(ctx, q, s.FetchConfig)
+	)
+	for it.Next() {
+		logs = append(logs, it.Log())
+	}
+	if err := it.Err(); err != nil {
+		return err
+	}
+
+	current := make(map[logKey]bool, len(logs))
+	for _, l := range logs {
+		current[logKey{l.BlockHash, l.Index}] = true
+	}
+	for key, l := range s.seen {
+		if l.BlockNumber >= from && l.BlockNumber <= head.Uint64() && !current[key] {
+			l.Removed = true
+			if err := s.deliver(ctx, ch, l); err != nil {
+				return err
+			}
+		}
+	}
+	for _, l := range logs {
+		if err := s.deliver(ctx, ch, l); err != nil {
+			return err
+		}
+	}
+	// Every log up to the head was delivered; later reorgs of that range are
+	// reported by the subscription or the filter.
+	if s.next <= head.Uint64() {
+		s.next = head.Uint64() + 1
+		s.prune()
+	}
+	return s.checkpoint(head.Uint64(), header.Hash())
+}
+
+// poll follows an eth_newFilter filter until it fails, recreating it and
+// backfilling when the node forgot it.
+func (s *LogStream) poll(ctx context.Context, ch chan<- types.Log) error {
+	id, err := s.client.eth.NewFilter(ctx, s.query)
+	if err != nil {
+		return err
+	}
+	defer s.client.eth.UninstallFilter(context.Background(), id)
+
+	if err := s.backfill(ctx, ch); err != nil {
+		return err
+	}
+	interval := s.PollInterval
+	if interval <= 0 {
+		interval = defaultPollInterval
+	}
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-ticker.C:
+		}
+		logs, err := s.client.eth.GetFilterChanges(ctx, id)
+		if err != nil {
+			return err
+		}
+		for _, l := range logs {
+			if err := s.deliver(ctx, ch, l); err != nil {
+				return err
+			}
+		}
+	}
+}
+
+// deliver forwards l unless it was already delivered, and moves the stream
+// position. Removed logs are only forwarded if their addition was.
+func (s *LogStream) deliver(ctx context.Context, ch chan<- types.Log, l types.Log) error {
+	key := logKey{l.BlockHash, l.Index}
+	_, seen := s.seen[key]
+	if l.Removed {
+		if !seen {
+			return nil
+		}
+		delete(s.seen, key)
+	} else {
+		if seen {
+			return nil
+		}
+		s.seen[key] = l
+		// Logs of this block may still be in flight, so it stays the lower
+		// bound of the next backfill.
+		if l.BlockNumber > s.next {
+			if s.Checkpoints != nil {
+				header, err := s.client.HeaderByHash(ctx, l.BlockHash)
+				if err != nil {
+					return err
+				}
+				if err := s.checkpoint(l.BlockNumber-1, header.ParentHash); err != nil {
+					return err
+				}
+			}
+			s.next = l.BlockNumber
+			s.prune()
+		}
+	}
+	select {
+	case ch <- l:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// restore moves the stream position past the saved checkpoint, if any.
+func (s *LogStream) restore(ctx context.Context) error {
+	if s.Checkpoints == nil {
+		return nil
+	}
+	cp, err := s.Checkpoints.Load(s.Name)
+	if err == ErrCheckpointNotFound {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	if err := s.client.VerifyCheckpoint(ctx, cp); err != nil {
+		return err
+	}
+	s.start, s.next, s.fromHead = cp.Number+1, cp.Number+1, false
+	return nil
+}
+
+// checkpoint saves number as the last block whose logs were all delivered.
+func (s *LogStream) checkpoint(number uint64, hash common.Hash) error {
+	if s.Checkpoints == nil {
+		return nil
+	}
+	return s.Checkpoints.Save(s.Name, Checkpoint{Number: number, Hash: hash, UpdatedAt: time.Now()})
+}
+
+// prune forgets logs too old to be redelivered.
+func (s *LogStream) prune() {
+	if s.next < logRetention {
+		return
+	}
+	limit := s.next - logRetention
+	for key, l := range s.seen {
+		if l.BlockNumber < limit {
+			delete(s.seen, key)
+		}
+	}
+}
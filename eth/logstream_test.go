@@ -0,0 +1,291 @@
+package eth
+
+import (
+	"context"
+	"errors"
+	"math/big"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/core/types"
+	ethrpc "github.com/ethereum/go-ethereum/rpc"
+)
+
+// errLogLimit is the error logService refuses oversized log queries with.
+var errLogLimit = errors.New("block range too large")
+
+// rangeFilter is the part of the eth_getLogs criteria used for block ranges.
+type rangeFilter struct {
+	FromBlock ethrpc.BlockNumber `json:"fromBlock"`
+	ToBlock   ethrpc.BlockNumber `json:"toBlock"`
+}
+
+// logService serves eth_getLogs over a fixed set of logs and pushes the logs
+// sent to live through the logs subscription.
+type logService struct {
+	*chainService
+	live chan types.Log
+
+	mu     sync.Mutex
+	logs   []types.Log
+	ranges [][2]int64 // block ranges of the eth_getLogs calls
+	// limit, when positive, rejects eth_getLogs ranges spanning more blocks;
+	// a negative limit rejects every query.
+	limit int64
+}
+
+func (s *logService) BlockNumber() hexutil.Uint64 {
+	return hexutil.Uint64(len(s.headers) - 1)
+}
+
+func (s *logService) GetLogs(crit rangeFilter) ([]types.Log, error) {
+	from, to := int64(crit.FromBlock), int64(crit.ToBlock)
+	if to < 0 {
+		to = int64(len(s.headers) - 1)
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.ranges = append(s.ranges, [2]int64{from, to})
+	if s.limit != 0 && to-from+1 > s.limit {
+		return nil, errLogLimit
+	}
+	var logs []types.Log
+	for _, l := range s.logs {
+		if int64(l.BlockNumber) >= from && int64(l.BlockNumber) <= to {
+			logs = append(logs, l)
+		}
+	}
+	return logs, nil
+}
+
+func (s *logService) Logs(ctx context.Context, crit interface{}) (*ethrpc.Subscription, error) {
+	notifier, ok := ethrpc.NotifierFromContext(ctx)
+	if !ok {
+		return nil, ethrpc.ErrNotificationsUnsupported
+	}
+	sub := notifier.CreateSubscription()
+	go func() {
+		for {
+			select {
+			case l := <-s.live:
+				notifier.Notify(sub.ID, l)
+			case <-sub.Err():
+				return
+			}
+		}
+	}()
+	return sub, nil
+}
+
+// queried returns the eth_getLogs ranges since the last call.
+func (s *logService) queried() [][2]int64 {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	ranges := s.ranges
+	s.ranges = nil
+	return ranges
+}
+
+// addLog puts a log into block number of the service's chain.
+func (s *logService) addLog(number int, index uint) types.Log {
+	l := types.Log{
+		Address:     testTokenA,
+		Topics:      []common.Hash{},
+		Data:        []byte{},
+		BlockNumber: uint64(number),
+		BlockHash:   s.headers[number].Hash(),
+		Index:       index,
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.logs = append(s.logs, l)
+	return l
+}
+
+func receiveLogs(t *testing.T, ch <-chan types.Log, n int) []types.Log {
+	t.Helper()
+	var logs []types.Log
+	for len(logs) < n {
+		select {
+		case l := <-ch:
+			logs = append(logs, l)
+		case <-time.After(5 * time.Second):
+			t.Fatalf("received %d logs, want %d", len(logs), n)
+		}
+	}
+	select {
+	case l := <-ch:
+		t.Fatalf("unexpected log %+v", l)
+	default:
+	}
+	return logs
+}
+
+func TestLogStreamBackfill(t *testing.T) {
+	var (
+		ctx     = context.Background()
+		service = &logService{chainService: &chainService{headers: testChain(10)}}
+		store   = NewMemoryCheckpointStore()
+		ch      = make(chan types.Log, 16)
+	)
+	service.addLog(0, 0)
+	service.addLog(3, 0)
+	service.addLog(7, 0)
+	c := newTestClient(t, map[string]interface{}{"eth": service})
+
+	// An explicit zero start block includes genesis.
+	s := c.NewLogStream(ethereum.FilterQuery{FromBlock: big.NewInt(0)})
+	s.Checkpoints, s.Name = store, "stream"
+	if err := s.backfill(ctx, ch); err != nil {
+		t.Fatal(err)
+	}
+	if logs := receiveLogs(t, ch, 3); logs[0].BlockNumber != 0 {
+		t.Fatalf("first log from block %d, want genesis", logs[0].BlockNumber)
+	}
+	if ranges := service.queried(); len(ranges) != 1 || ranges[0] != [2]int64{0, 9} {
+		t.Fatalf("backfill ranges mismatch: have %v", ranges)
+	}
+
+	// The next backfill covers the blocks below the previous head again, but
+	// only delivers the new log.
+	service.headers = append(service.headers, testChain(13)[10:]...)
+	service.addLog(11, 0)
+	if err := s.backfill(ctx, ch); err != nil {
+		t.Fatal(err)
+	}
+	if logs := receiveLogs(t, ch, 1); logs[0].BlockNumber != 11 {
+		t.Fatalf("log from block %d, want 11", logs[0].BlockNumber)
+	}
+	if ranges := service.queried(); len(ranges) != 1 || ranges[0] != [2]int64{0, 12} {
+		t.Fatalf("backfill ranges mismatch: have %v", ranges)
+	}
+
+	cp, err := store.Load("stream")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if cp.Number != 12 || cp.Hash != service.headers[12].Hash() {
+		t.Fatalf("checkpoint mismatch: have %d %x", cp.Number, cp.Hash)
+	}
+
+	// A new stream resumes from the checkpoint.
+	resumed := c.NewLogStream(ethereum.FilterQuery{FromBlock: big.NewInt(0)})
+	resumed.Checkpoints, resumed.Name = store, "stream"
+	if err := resumed.restore(ctx); err != nil {
+		t.Fatal(err)
+	}
+	if resumed.next != 13 {
+		t.Fatalf("resumed position mismatch: have %d, want 13", resumed.next)
+	}
+}
+
+func TestLogStreamBackfillReorg(t *testing.T) {
+	var (
+		ctx     = context.Background()
+		service = &logService{chainService: &chainService{headers: testChain(10)}}
+		ch      = make(chan types.Log, 16)
+	)
+	service.addLog(3, 0)
+	orphaned := service.addLog(7, 0)
+	c := newTestClient(t, map[string]interface{}{"eth": service})
+
+	s := c.NewLogStream(ethereum.FilterQuery{FromBlock: big.NewInt(2)})
+	if err := s.backfill(ctx, ch); err != nil {
+		t.Fatal(err)
+	}
+	receiveLogs(t, ch, 2)
+	if s.next != 10 {
+		t.Fatalf("stream position mismatch: have %d, want 10", s.next)
+	}
+
+	// While disconnected, blocks 7 to 9 are replaced and the log of block 7
+	// moves to block 8 of the new branch.
+	for i := 7; i < 10; i++ {
+		header := testHeader(int64(i), service.headers[i-1])
+		header.Extra = []byte("fork")
+		service.headers[i] = header
+	}
+	service.mu.Lock()
+	service.logs = service.logs[:1]
+	service.mu.Unlock()
+	moved := service.addLog(8, 0)
+
+	if err := s.backfill(ctx, ch); err != nil {
+		t.Fatal(err)
+	}
+	logs := receiveLogs(t, ch, 2)
+	if !logs[0].Removed || logs[0].BlockHash != orphaned.BlockHash {
+		t.Fatalf("removed log mismatch: have %+v", logs[0])
+	}
+	if logs[1].Removed || logs[1].BlockHash != moved.BlockHash {
+		t.Fatalf("new branch log mismatch: have %+v", logs[1])
+	}
+	// The backfill never goes below the start block of the query.
+	if ranges := service.queried(); len(ranges) != 2 || ranges[1] != [2]int64{2, 9} {
+		t.Fatalf("backfill ranges mismatch: have %v", ranges)
+	}
+}
+
+func TestLogStreamBackfillChunked(t *testing.T) {
+	service := &logService{chainService: &chainService{headers: testChain(10)}, limit: 4}
+	for i := 0; i < 10; i++ {
+		service.addLog(i, 0)
+	}
+	c := newTestClient(t, map[string]interface{}{"eth": service})
+	ch := make(chan types.Log, 16)
+
+	s := c.NewLogStream(ethereum.FilterQuery{FromBlock: big.NewInt(0)})
+	if err := s.backfill(context.Background(), ch); err != nil {
+		t.Fatal(err)
+	}
+	for i, l := range receiveLogs(t, ch, 10) {
+		if l.BlockNumber != uint64(i) {
+			t.Fatalf("log %d from block %d", i, l.BlockNumber)
+		}
+	}
+}
+
+func TestLogStreamFromHead(t *testing.T) {
+	service := &logService{
+		chainService: &chainService{headers: testChain(8)},
+		live:         make(chan types.Log),
+	}
+	service.addLog(2, 0) // before the head, never delivered
+	head := service.addLog(7, 0)
+	c := newTestClient(t, map[string]interface{}{"eth": service})
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	ch := make(chan types.Log, 16)
+	s := c.NewLogStream(ethereum.FilterQuery{})
+	done := make(chan error, 1)
+	go func() { done <- s.Run(ctx, ch) }()
+
+	if logs := receiveLogs(t, ch, 1); logs[0].BlockHash != head.BlockHash {
+		t.Fatalf("first log from block %d, want the head", logs[0].BlockNumber)
+	}
+	live := types.Log{Address: testTokenA, Topics: []common.Hash{}, Data: []byte{}, BlockNumber: 8, BlockHash: common.Hash{0x08}}
+	service.live <- live
+	if logs := receiveLogs(t, ch, 1); logs[0].BlockHash != live.BlockHash {
+		t.Fatalf("live log mismatch: have %+v", logs[0])
+	}
+	// The same log delivered again, e.g. after a reconnect, is dropped.
+	service.live <- live
+	select {
+	case l := <-ch:
+		t.Fatalf("duplicate log delivered: %+v", l)
+	case <-time.After(100 * time.Millisecond):
+	}
+
+	cancel()
+	if err := <-done; err != context.Canceled {
+		t.Fatalf("Run error mismatch: have %v, want %v", err, context.Canceled)
+	}
+	if ranges := service.queried(); len(ranges) != 1 || ranges[0] != [2]int64{7, 7} {
+		t.Fatalf("backfill ranges mismatch: have %v", ranges)
+	}
+}
@@ -0,0 +1,175 @@
+package eth
+
+import (
+	"context"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/ethereum/go-ethereum/common"
+)
+
+// NonceSource is the part of the client the nonce manager syncs from.
+type NonceSource interface {
+	PendingNonceAt(ctx context.Context, account common.Address) (uint64, error)
+}
+
+// NonceManager hands out sequential nonces per sender so that concurrent
+// senders using the same key never race for the same nonce. A single manager
+// may be shared by several clients talking to the same chain.
+type NonceManager struct {
+	mu       sync.Mutex
+	accounts map[common.Address]*nonceAccount
+}
+
+type nonceAccount struct {
+	mu       sync.Mutex
+	synced   bool
+	next     uint64
+	released []uint64 // sorted nonces handed out but never broadcast
+}
+
+// NewNonceManager creates an empty nonce manager.
+func NewNonceManager() *NonceManager {
+	return &NonceManager{
+		accounts: make(map[common.Address]*nonceAccount),
+	}
+}
+
+func (m *NonceManager) account(addr common.Address) *nonceAccount {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	acc, ok := m.accounts[addr]
+	if !ok {
+		acc = &nonceAccount{}
+		m.accounts[addr] = acc
+	}
+	return acc
+}
+
+// Next reserves the next nonce of addr. The first call for an account, and the
+// first call after Resync or Reset, reads the pending nonce from src. Released
+// nonces are handed out again, lowest first, before new ones.
+func (m *NonceManager) Next(ctx context.Context, src NonceSource, addr common.Address) (uint64, error) {
+	acc := m.account(addr)
+	acc.mu.Lock()
+	defer acc.mu.Unlock()
+
+	if !acc.synced {
+		nonce, err := src.PendingNonceAt(ctx, addr)
+		if err != nil {
+			return 0, err
+		}
+		acc.next, acc.synced, acc.released = nonce, true, nil
+	}
+	if len(acc.released) > 0 {
+		nonce := acc.released[0]
+		acc.released = acc.released[1:]
+		return nonce, nil
+	}
+	nonce := acc.next
+	acc.next++
+	return nonce, nil
+}
+
+// Release returns a nonce obtained from Next whose transaction was never
+// broadcast, so the gap it would leave is filled by the next send.
+func (m *NonceManager) Release(addr common.Address, nonce uint64) {
+	acc := m.account(addr)
+	acc.mu.Lock()
+	defer acc.mu.Unlock()
+
+	if !acc.synced || nonce >= acc.next {
+		return
+	}
+	i := sort.Search(len(acc.released), func(i int) bool { return acc.released[i] >= nonce })
+	if i < len(acc.released) && acc.released[i] == nonce {
+		return
+	}
+	acc.released = append(acc.released, 0)
+	copy(acc.released[i+1:], acc.released[i:])
+	acc.released[i] = nonce
+
+	// Shrink the counter while the highest nonces are unused.
+	for n := len(acc.released); n > 0 && acc.released[n-1] == acc.next-1; n-- {
+		acc.released = acc.released[:n-1]
+		acc.next--
+	}
+}
+
+// Resync catches the nonce of addr up with the pending nonce of src, dropping
+// the released nonces below it. The counter never moves back: nonces reserved
+// by other senders may not have reached the node yet. Use Reset to discard
+// nonces whose transactions were dropped.
+func (m *NonceManager) Resync(ctx context.Context, src NonceSource, addr common.Address) error {
+	acc := m.account(addr)
+	acc.mu.Lock()
+	defer acc.mu.Unlock()
+
+	nonce, err := src.PendingNonceAt(ctx, addr)
+	if err != nil {
+		return err
+	}
+	if !acc.synced || nonce > acc.next {
+		acc.next = nonce
+	}
+	i := sort.Search(len(acc.released), func(i int) bool { return acc.released[i] >= nonce })
+	acc.released, acc.synced = acc.released[i:], true
+	return nil
+}
+
+// Reset forgets addr; the next call to Next reads the nonce from the node.
+func (m *NonceManager) Reset(addr common.Address) {
+	acc := m.account(addr)
+	acc.mu.Lock()
+	defer acc.mu.Unlock()
+	acc.synced, acc.released = false, nil
+}
+
+// IsNonceError reports whether err is a node rejection caused by a stale
+// nonce, after which the local nonce must be resynced.
+func IsNonceError(err error) bool {
+	if err == nil {
+		return false
+	}
+	return strings.Contains(strings.ToLower(err.Error()), "nonce too low")
+}
+
+// isKnownTransaction reports whether err is a node rejection of a transaction
+// that is already in its pool. The transaction itself was broadcast, so it must
+// not be sent again under another nonce.
+func isKnownTransaction(err error) bool {
+	if err == nil {
+		return false
+	}
+	msg := strings.ToLower(err.Error())
+	return strings.Contains(msg, "known transaction") || strings.Contains(msg, "already known")
+}
+
+// SetNonceManager makes the client allocate nonces from m, which may be shared
+// with other clients. Passing nil makes every send query the pending nonce.
+func (c *ClientTokenEth) SetNonceManager(m *NonceManager) {
+	c.nonces = m
+}
+
+func (c *ClientTokenEth) nextNonce(ctx context.Context, from common.Address) (uint64, error) {
+	if c.nonces == nil {
+		return c.PendingNonceAt(ctx, from)
+	}
+	return c.nonces.Next(ctx, c, from)
+}
+
+func (c *ClientTokenEth) releaseNonce(from common.Address, nonce uint64) {
+	if c.nonces != nil {
+		c.nonces.Release(from, nonce)
+	}
+}
+
+func (c *ClientTokenEth) resyncNonce(ctx context.Context, from common.Address) {
+	if c.nonces == nil {
+		return
+	}
+	if err := c.nonces.Resync(ctx, c, from); err != nil {
+		log.Warn("Failed to resync nonce", "account", from, "err", err)
+	}
+}
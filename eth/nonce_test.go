@@ -0,0 +1,210 @@
+package eth
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+)
+
+type fixedNonce uint64
+
+func (n fixedNonce) PendingNonceAt(ctx context.Context, account common.Address) (uint64, error) {
+	return uint64(n), nil
+}
+
+func TestNonceManager(t *testing.T) {
+	var (
+		ctx  = context.Background()
+		addr = common.HexToAddress("0x3fd3adba69955f85bc34860b77a64c2c52c981ea")
+		m    = NewNonceManager()
+	)
+	next := func(want uint64) {
+		t.Helper()
+		got, err := m.Next(ctx, fixedNonce(5), addr)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got != want {
+			t.Fatalf("nonce mismatch: have %d, want %d", got, want)
+		}
+	}
+
+	next(5)
+	next(6)
+	next(7)
+
+	// A gap in the middle is reused before new nonces.
+	m.Release(addr, 6)
+	next(6)
+	next(8)
+
+	// Releasing the highest nonces rewinds the counter.
+	m.Release(addr, 7)
+	m.Release(addr, 8)
+	next(7)
+
+	// Resyncing with a node that has not seen the reserved nonces yet keeps
+	// them reserved.
+	if err := m.Resync(ctx, fixedNonce(5), addr); err != nil {
+		t.Fatal(err)
+	}
+	next(8)
+
+	// Released nonces below the pending nonce are dropped, others kept.
+	next(9)
+	m.Release(addr, 6)
+	m.Release(addr, 8)
+	if err := m.Resync(ctx, fixedNonce(7), addr); err != nil {
+		t.Fatal(err)
+	}
+	next(8)
+	next(10)
+
+	if err := m.Resync(ctx, fixedNonce(42), addr); err != nil {
+		t.Fatal(err)
+	}
+	got, _ := m.Next(ctx, fixedNonce(0), addr)
+	if got != 42 {
+		t.Fatalf("nonce after resync mismatch: have %d, want %d", got, 42)
+	}
+}
+
+func TestIsNonceError(t *testing.T) {
+	tests := []struct {
+		err  error
+		want bool
+	}{
+		{nil, false},
+		{errors.New("nonce too low"), true},
+		{errors.New("known transaction: 0x7e41"), false},
+		{errors.New("already known"), false},
+		{errors.New("insufficient funds for gas * price + value"), false},
+	}
+	for _, tt := range tests {
+		if got := IsNonceError(tt.err); got != tt.want {
+			t.Errorf("IsNonceError(%v) = %v, want %v", tt.err, got, tt.want)
+		}
+	}
+}
+
+func TestNonceManagerConcurrent(t *testing.T) {
+	const (
+		senders = 16
+		sends   = 50
+		start   = 100
+	)
+	var (
+		ctx  = context.Background()
+		addr = common.HexToAddress("0x3fd3adba69955f85bc34860b77a64c2c52c981ea")
+		m    = NewNonceManager()
+		wg   sync.WaitGroup
+	)
+	nonces := make(chan uint64, senders*sends)
+	for i := 0; i < senders; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			for j := 0; j < sends; j++ {
+				nonce, err := m.Next(ctx, fixedNonce(start), addr)
+				if err != nil {
+					t.Error(err)
+					return
+				}
+				// Give some nonces back, as failed sends do.
+				if (i+j)%7 == 0 {
+					m.Release(addr, nonce)
+					continue
+				}
+				nonces <- nonce
+			}
+		}(i)
+	}
+	wg.Wait()
+	close(nonces)
+
+	seen := make(map[uint64]bool)
+	var highest uint64
+	for nonce := range nonces {
+		if seen[nonce] {
+			t.Fatalf("nonce %d handed out twice", nonce)
+		}
+		seen[nonce] = true
+		if nonce > highest {
+			highest = nonce
+		}
+	}
+	// Released nonces left over sit below the highest used one; drain them so
+	// that the remaining range must be contiguous.
+	var next uint64
+	for {
+		next, _ = m.Next(ctx, fixedNonce(start), addr)
+		if next > highest {
+			break
+		}
+		if seen[next] {
+			t.Fatalf("nonce %d handed out twice", next)
+		}
+		seen[next] = true
+	}
+	if next != highest+1 {
+		t.Fatalf("counter mismatch: have %d, want %d", next, highest+1)
+	}
+	for n := uint64(start); n <= highest; n++ {
+		if !seen[n] {
+			t.Fatalf("nonce gap at %d", n)
+		}
+	}
+}
+
+func TestNonceManagerConcurrentResync(t *testing.T) {
+	const (
+		senders = 16
+		sends   = 50
+		start   = 100
+	)
+	var (
+		ctx  = context.Background()
+		addr = common.HexToAddress("0x3fd3adba69955f85bc34860b77a64c2c52c981ea")
+		m    = NewNonceManager()
+		wg   sync.WaitGroup
+	)
+	nonces := make(chan uint64, senders*sends)
+	for i := 0; i < senders; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			for j := 0; j < sends; j++ {
+				nonce, err := m.Next(ctx, fixedNonce(start), addr)
+				if err != nil {
+					t.Error(err)
+					return
+				}
+				nonces <- nonce
+				// Some sends fail with a stale nonce and resync while the
+				// node has not seen any of the reserved nonces yet.
+				if (i+j)%5 == 0 {
+					if err := m.Resync(ctx, fixedNonce(start), addr); err != nil {
+						t.Error(err)
+						return
+					}
+				}
+			}
+		}(i)
+	}
+	wg.Wait()
+	close(nonces)
+
+	seen := make(map[uint64]bool)
+	for nonce := range nonces {
+		if seen[nonce] {
+			t.Fatalf("nonce %d handed out twice", nonce)
+		}
+		seen[nonce] = true
+	}
+	if next, _ := m.Next(ctx, fixedNonce(start), addr); next != start+senders*sends {
+		t.Fatalf("counter mismatch: have %d, want %d", next, start+senders*sends)
+	}
+}
@@ -0,0 +1,315 @@
+package eth
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/ethereum/go-ethereum/p2p"
+	"github.com/ethereum/go-ethereum/p2p/enode"
+	"github.com/tokenchain/eth-client/eth/rpc"
+)
+
+// ErrPeerRejected is returned when the node refuses an admin peer request.
+var ErrPeerRejected = errors.New("peer request rejected by node")
+
+// maxPeerHistory is the number of connection events kept per peer.
+const maxPeerHistory = 32
+
+// ParseEnode validates the syntax of an enode URL, including its public key.
+func ParseEnode(url string) (*enode.Node, error) {
+	node, err := enode.ParseV4(url)
+	if err != nil {
+		return nil, fmt.Errorf("invalid enode URL %q: %v", url, err)
+	}
+	return node, nil
+}
+
+// PeerEventKind tells what happened to a managed peer.
+type PeerEventKind int
+
+const (
+	PeerConnected PeerEventKind = iota
+	PeerDisconnected
+	PeerAddFailed
+)
+
+func (k PeerEventKind) String() string {
+	switch k {
+	case PeerConnected:
+		return "connected"
+	case PeerDisconnected:
+		return "disconnected"
+	case PeerAddFailed:
+		return "add failed"
+	}
+	return fmt.Sprintf("PeerEventKind(%d)", int(k))
+}
+
+// PeerEvent is an entry of a managed peer's connection history.
+type PeerEvent struct {
+	Time time.Time
+	Kind PeerEventKind
+	Err  error // set for PeerAddFailed
+}
+
+// PeerStatus reports the state of a managed peer.
+type PeerStatus struct {
+	URL       string
+	ID        enode.ID
+	Trusted   bool
+	Connected bool
+	// History holds the latest connection events, oldest first.
+	History []PeerEvent
+}
+
+type managedPeer struct {
+	url          string
+	trusted      bool
+	trustApplied bool // trust as last set on the node
+	connected    bool
+	history      []PeerEvent
+}
+
+func (p *managedPeer) record(kind PeerEventKind, err error) {
+	p.history = append(p.history, PeerEvent{Time: time.Now(), Kind: kind, Err: err})
+	if len(p.history) > maxPeerHistory {
+		p.history = p.history[len(p.history)-maxPeerHistory:]
+	}
+}
+
+// PeerManager keeps a node connected to a desired set of static peers. Each
+// Reconcile compares the set against admin_peers and re-adds the missing
+// peers; in exclusive mode it also disconnects peers outside the set. It is
+// safe for concurrent use.
+type PeerManager struct {
+	admin rpc.Admin
+
+	mu        sync.Mutex
+	peers     map[enode.ID]*managedPeer
+	exclusive bool
+}
+
+// NewPeerManager creates a manager with an empty desired set.
+func NewPeerManager(admin rpc.Admin) *PeerManager {
+	return &PeerManager{
+		admin: admin,
+		peers: make(map[enode.ID]*managedPeer),
+	}
+}
+
+// PeerManager creates a peer manager for the client's node.
+func (c *ClientTokenEth) PeerManager() *PeerManager {
+	return NewPeerManager(c.admin)
+}
+
+// SetExclusive sets whether Reconcile disconnects peers outside the desired set.
+func (m *PeerManager) SetExclusive(exclusive bool) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.exclusive = exclusive
+}
+
+// Add puts the peer into the desired set. Trusted peers are also added with
+// admin_addTrustedPeer, letting them connect even when the node is full. The
+// peer is connected, and a change of its trust applied, by the next Reconcile.
+func (m *PeerManager) Add(url string, trusted bool) error {
+	node, err := ParseEnode(url)
+	if err != nil {
+		return err
+	}
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if p, ok := m.peers[node.ID()]; ok {
+		p.url, p.trusted = url, trusted
+		return nil
+	}
+	m.peers[node.ID()] = &managedPeer{url: url, trusted: trusted}
+	return nil
+}
+
+// Remove takes the peer out of the desired set and disconnects it.
+func (m *PeerManager) Remove(ctx context.Context, url string) error {
+	node, err := ParseEnode(url)
+	if err != nil {
+		return err
+	}
+	m.mu.Lock()
+	p, ok := m.peers[node.ID()]
+	delete(m.peers, node.ID())
+	m.mu.Unlock()
+
+	if ok && (p.trusted || p.trustApplied) {
+		if _, err := m.admin.RemoveTrustedPeer(ctx, url); err != nil {
+			return err
+		}
+	}
+	_, err = m.admin.RemovePeer(ctx, url)
+	return err
+}
+
+// Reconcile applies pending trust changes, connects the desired peers missing
+// from admin_peers and, in exclusive mode, disconnects the others. Failures to
+// add single peers are recorded in their history rather than returned; failed
+// trust changes and disconnections are retried by the next Reconcile and
+// returned together once all were attempted.
+func (m *PeerManager) Reconcile(ctx context.Context) error {
+	infos, err := m.admin.Peers(ctx)
+	if err != nil {
+		return err
+	}
+	connected := make(map[enode.ID]*p2p.PeerInfo, len(infos))
+	for _, info := range infos {
+		if node, err := enode.ParseV4(info.Enode); err == nil {
+			connected[node.ID()] = info
+		} else if id, err := enode.ParseID(info.ID); err == nil {
+			connected[id] = info
+		}
+	}
+
+	type target struct {
+		id      enode.ID
+		url     string
+		trusted bool
+	}
+	m.mu.Lock()
+	var missing, trust []target
+	for id, p := range m.peers {
+		if p.trusted != p.trustApplied {
+			trust = append(trust, target{id, p.url, p.trusted})
+		}
+		_, ok := connected[id]
+		switch {
+		case ok && !p.connected:
+			p.connected = true
+			p.record(PeerConnected, nil)
+		case !ok && p.connected:
+			p.connected = false
+			p.record(PeerDisconnected, nil)
+			log.Warn("Managed peer disconnected", "url", p.url)
+		}
+		if !ok {
+			missing = append(missing, target{id, p.url, p.trusted})
+		}
+	}
+	var extra []string
+	if m.exclusive {
+		for id, info := range connected {
+			if _, ok := m.peers[id]; !ok && info.Enode != "" {
+				extra = append(extra, info.Enode)
+			}
+		}
+	}
+	m.mu.Unlock()
+
+	var errs []error
+	for _, t := range trust {
+		if err := m.setTrust(ctx, t.url, t.trusted); err != nil {
+			if ctx.Err() != nil {
+				return ctx.Err()
+			}
+			errs = append(errs, fmt.Errorf("failed to set trust of %s: %w", t.url, err))
+			continue
+		}
+		m.mu.Lock()
+		if p, ok := m.peers[t.id]; ok && p.trusted == t.trusted {
+			p.trustApplied = t.trusted
+		}
+		m.mu.Unlock()
+	}
+	for _, t := range missing {
+		if err := m.connect(ctx, t.url); err != nil {
+			if ctx.Err() != nil {
+				return ctx.Err()
+			}
+			log.Debug("Failed to add managed peer", "url", t.url, "err", err)
+			m.mu.Lock()
+			if p, ok := m.peers[t.id]; ok {
+				p.record(PeerAddFailed, err)
+			}
+			m.mu.Unlock()
+		}
+	}
+	for _, url := range extra {
+		if _, err := m.admin.RemovePeer(ctx, url); err != nil {
+			if ctx.Err() != nil {
+				return ctx.Err()
+			}
+			errs = append(errs, fmt.Errorf("failed to disconnect %s: %w", url, err))
+			continue
+		}
+		log.Info("Disconnected unmanaged peer", "url", url)
+	}
+	return errors.Join(errs...)
+}
+
+// setTrust adds the peer to the node's trusted set or removes it from it.
+func (m *PeerManager) setTrust(ctx context.Context, url string, trusted bool) error {
+	var (
+		ok  bool
+		err error
+	)
+	if trusted {
+		ok, err = m.admin.AddTrustedPeer(ctx, url)
+	} else {
+		ok, err = m.admin.RemoveTrustedPeer(ctx, url)
+	}
+	if err != nil {
+		return err
+	}
+	if !ok {
+		return ErrPeerRejected
+	}
+	return nil
+}
+
+func (m *PeerManager) connect(ctx context.Context, url string) error {
+	ok, err := m.admin.AddPeer(ctx, url)
+	if err != nil {
+		return err
+	}
+	if !ok {
+		return ErrPeerRejected
+	}
+	return nil
+}
+
+// Run reconciles every interval until ctx is cancelled. Reconcile errors are
+// logged and retried at the next tick.
+func (m *PeerManager) Run(ctx context.Context, interval time.Duration) error {
+	if interval <= 0 {
+		interval = defaultPollInterval
+	}
+	tick := pollTicker(ctx, interval)
+	for {
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-tick:
+			if err := m.Reconcile(ctx); err != nil && ctx.Err() == nil {
+				log.Warn("Failed to reconcile peers", "err", err)
+			}
+		}
+	}
+}
+
+// Status reports the managed peers as of the last Reconcile, sorted by URL.
+func (m *PeerManager) Status() []PeerStatus {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	statuses := make([]PeerStatus, 0, len(m.peers))
+	for id, p := range m.peers {
+		statuses = append(statuses, PeerStatus{
+			URL:       p.url,
+			ID:        id,
+			Trusted:   p.trusted,
+			Connected: p.connected,
+			History:   append([]PeerEvent(nil), p.history...),
+		})
+	}
+	sort.Slice(statuses, func(i, j int) bool { return statuses[i].URL < statuses[j].URL })
+	return statuses
+}
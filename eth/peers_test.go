@@ -0,0 +1,169 @@
+package eth
+
+import (
+	"context"
+	"errors"
+	"net"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/ethereum/go-ethereum/p2p"
+	"github.com/ethereum/go-ethereum/p2p/enode"
+	"github.com/tokenchain/eth-client/eth/rpc"
+)
+
+// fakeAdmin serves admin_peers from a fixed set and records the peer
+// requests. Removing a peer listed in removeErr fails with its error.
+type fakeAdmin struct {
+	rpc.Admin
+	peers     []*p2p.PeerInfo
+	added     []string
+	trusted   []string
+	untrusted []string
+	removed   []string
+	removeErr map[string]error
+}
+
+func (a *fakeAdmin) Peers(ctx context.Context) ([]*p2p.PeerInfo, error) { return a.peers, nil }
+
+func (a *fakeAdmin) AddPeer(ctx context.Context, url string) (bool, error) {
+	a.added = append(a.added, url)
+	return true, nil
+}
+
+func (a *fakeAdmin) AddTrustedPeer(ctx context.Context, url string) (bool, error) {
+	a.trusted = append(a.trusted, url)
+	return true, nil
+}
+
+func (a *fakeAdmin) RemoveTrustedPeer(ctx context.Context, url string) (bool, error) {
+	a.untrusted = append(a.untrusted, url)
+	return true, nil
+}
+
+func (a *fakeAdmin) RemovePeer(ctx context.Context, url string) (bool, error) {
+	a.removed = append(a.removed, url)
+	if err := a.removeErr[url]; err != nil {
+		return false, err
+	}
+	return true, nil
+}
+
+func newTestEnode(t *testing.T) string {
+	key, err := crypto.GenerateKey()
+	if err != nil {
+		t.Fatal(err)
+	}
+	return enode.NewV4(&key.PublicKey, net.ParseIP("127.0.0.1"), 30303, 30303).URLv4()
+}
+
+func TestPeerManagerReconcile(t *testing.T) {
+	var (
+		up       = newTestEnode(t)
+		down     = newTestEnode(t)
+		stranger = newTestEnode(t)
+		admin    = &fakeAdmin{peers: []*p2p.PeerInfo{{Enode: up}, {Enode: stranger}}}
+		m        = NewPeerManager(admin)
+	)
+	if err := m.Add("enode://bogus@127.0.0.1:30303", false); err == nil {
+		t.Fatal("invalid enode URL accepted")
+	}
+	if err := m.Add(up, false); err != nil {
+		t.Fatal(err)
+	}
+	if err := m.Add(down, true); err != nil {
+		t.Fatal(err)
+	}
+	m.SetExclusive(true)
+
+	if err := m.Reconcile(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	if len(admin.added) != 1 || admin.added[0] != down || len(admin.trusted) != 1 {
+		t.Fatalf("unexpected peers added: %v, trusted %v", admin.added, admin.trusted)
+	}
+	if len(admin.removed) != 1 || admin.removed[0] != stranger {
+		t.Fatalf("unexpected peers removed: %v", admin.removed)
+	}
+
+	// The node dropped the peer that was up and picked up the missing one.
+	admin.peers = []*p2p.PeerInfo{{Enode: down}}
+	if err := m.Reconcile(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	for _, status := range m.Status() {
+		var want []PeerEventKind
+		switch status.URL {
+		case up:
+			want = []PeerEventKind{PeerConnected, PeerDisconnected}
+		case down:
+			want = []PeerEventKind{PeerConnected}
+		}
+		if len(status.History) != len(want) {
+			t.Fatalf("%s: unexpected history %+v", status.URL, status.History)
+		}
+		for i, ev := range status.History {
+			if ev.Kind != want[i] {
+				t.Errorf("%s: event %d is %v, want %v", status.URL, i, ev.Kind, want[i])
+			}
+		}
+	}
+}
+
+func TestPeerManagerTrust(t *testing.T) {
+	var (
+		ctx   = context.Background()
+		peer  = newTestEnode(t)
+		admin = &fakeAdmin{peers: []*p2p.PeerInfo{{Enode: peer}}}
+		m     = NewPeerManager(admin)
+	)
+	if err := m.Add(peer, true); err != nil {
+		t.Fatal(err)
+	}
+	for i := 0; i < 2; i++ {
+		if err := m.Reconcile(ctx); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if len(admin.trusted) != 1 || admin.trusted[0] != peer {
+		t.Fatalf("unexpected trusted peers: %v", admin.trusted)
+	}
+
+	// Dropping the trust of a connected peer is applied once.
+	if err := m.Add(peer, false); err != nil {
+		t.Fatal(err)
+	}
+	for i := 0; i < 2; i++ {
+		if err := m.Reconcile(ctx); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if len(admin.untrusted) != 1 || admin.untrusted[0] != peer {
+		t.Fatalf("unexpected untrusted peers: %v", admin.untrusted)
+	}
+	if len(admin.trusted) != 1 || len(admin.added) != 0 {
+		t.Fatalf("unexpected peer requests: added %v, trusted %v", admin.added, admin.trusted)
+	}
+}
+
+func TestPeerManagerExclusiveErrors(t *testing.T) {
+	var (
+		first  = newTestEnode(t)
+		second = newTestEnode(t)
+		failed = errors.New("remove failed")
+		admin  = &fakeAdmin{
+			peers:     []*p2p.PeerInfo{{Enode: first}, {Enode: second}},
+			removeErr: map[string]error{first: failed, second: failed},
+		}
+		m = NewPeerManager(admin)
+	)
+	m.SetExclusive(true)
+
+	err := m.Reconcile(context.Background())
+	if !errors.Is(err, failed) {
+		t.Fatalf("error mismatch: have %v, want %v", err, failed)
+	}
+	if len(admin.removed) != 2 {
+		t.Fatalf("removal stopped at the first failure: %v", admin.removed)
+	}
+}
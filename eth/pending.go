@@ -0,0 +1,268 @@
+package eth
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"errors"
+	"math/big"
+	"time"
+
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	ethrpc "github.com/ethereum/go-ethereum/rpc"
+)
+
+var (
+	// ErrTxFailed is returned by Wait when the transaction was mined but reverted.
+	ErrTxFailed = errors.New("transaction execution failed")
+	// ErrTxReplaced is returned by Wait when another transaction with the same
+	// nonce was mined instead.
+	ErrTxReplaced = errors.New("transaction replaced by another with the same nonce")
+	// ErrTxDropped is returned by Wait when the node no longer knows the
+	// transaction and its nonce is still unused.
+	ErrTxDropped = errors.New("transaction dropped from the pool")
+)
+
+const (
+	// defaultPollInterval is how often Wait polls when the endpoint cannot push
+	// new heads, e.g. plain HTTP.
+	defaultPollInterval = 4 * time.Second
+	// droppedAfterMisses is the number of consecutive checks the transaction
+	// must be unknown to the node before it is considered dropped.
+	droppedAfterMisses = 3
+)
+
+// PendingTransaction is a handle on a transaction broadcast by the client.
+type PendingTransaction struct {
+	// PollInterval is used by Wait when head subscriptions are unavailable.
+	PollInterval time.Duration
+
+	client *ClientTokenEth
+	tx     *types.Transaction
+	from   common.Address
+}
+
+// NewPendingTransaction returns a handle on a transaction signed by from that
+// was broadcast by other means than the client.
+func (c *ClientTokenEth) NewPendingTransaction(tx *types.Transaction, from common.Address) *PendingTransaction {
+	return &PendingTransaction{
+		PollInterval: defaultPollInterval,
+		client:       c,
+		tx:           tx,
+		from:         from,
+	}
+}
+
+// Hash returns the hash of the signed transaction.
+func (p *PendingTransaction) Hash() common.Hash {
+	return p.tx.Hash()
+}
+
+// Transaction returns the signed transaction.
+func (p *PendingTransaction) Transaction() *types.Transaction {
+	return p.tx
+}
+
+// From returns the sender of the transaction.
+func (p *PendingTransaction) From() common.Address {
+	return p.from
+}
+
+// Nonce returns the nonce of the transaction.
+func (p *PendingTransaction) Nonce() uint64 {
+	return p.tx.Nonce()
+}
+
+// Wait blocks until the transaction is included and buried under the given
+// number of confirmations (the including block counts as the first one). It
+// follows new heads through eth_subscribe when the endpoint supports it and
+// polls otherwise. The receipt is returned together with ErrTxFailed if the
+// transaction reverted; ErrTxReplaced and ErrTxDropped report transactions
+// that will never be mined.
+func (p *PendingTransaction) Wait(ctx context.Context, confirmations uint64) (*types.Receipt, error) {
+	if confirmations == 0 {
+		confirmations = 1
+	}
+
+	var (
+		heads   = make(chan *types.Header, 16)
+		tick    <-chan time.Time
+		subErr  <-chan error
+		misses  int
+		polling = func() {
+			interval := p.PollInterval
+			if interval <= 0 {
+				interval = defaultPollInterval
+			}
+			ticker := time.NewTicker(interval)
+			tick = ticker.C
+			go func() {
+				<-ctx.Done()
+				ticker.Stop()
+			}()
+		}
+	)
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	sub, err := p.client.SubscribeNewHead(ctx, heads)
+	if err != nil {
+		polling()
+	} else {
+		defer sub.Unsubscribe()
+		subErr = sub.Err()
+	}
+
+	for {
+		receipt, done, err := p.check(ctx, confirmations, &misses)
+		if done {
+			return receipt, err
+		}
+		if err != nil {
+			log.Debug("Failed to check pending transaction", "hash", p.Hash(), "err", err)
+		}
+
+		select {
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		case <-heads:
+		case <-tick:
+		case err := <-subErr:
+			log.Debug("Head subscription lost, polling instead", "err", err)
+			subErr = nil
+			polling()
+		}
+	}
+}
+
+// check performs a single inspection of the transaction. done is true when
+// Wait should return receipt and err to its caller.
+func (p *PendingTransaction) check(ctx context.Context, confirmations uint64, misses *int) (receipt *types.Receipt, done bool, err error) {
+	receipt, err = p.client.TransactionReceipt(ctx, p.Hash())
+	switch {
+	case err == ethereum.NotFound:
+		return nil, p.gone(ctx, misses)
+	case err != nil:
+		return nil, false, err
+	}
+	*misses = 0
+
+	head, err := p.client.HeaderByNumber(ctx, nil)
+	if err != nil {
+		return nil, false, err
+	}
+	depth := new(big.Int).Sub(head.Number, receipt.BlockNumber)
+	if depth.Sign() < 0 || depth.Uint64()+1 < confirmations {
+		return nil, false, nil
+	}
+	if receipt.Status == types.ReceiptStatusFailed {
+		return receipt, true, ErrTxFailed
+	}
+	return receipt, true, nil
+}
+
+// gone works out whether a transaction without receipt was replaced, dropped
+// or is simply still pending.
+func (p *PendingTransaction) gone(ctx context.Context, misses *int) (bool, error) {
+	nonce, err := p.client.NonceAt(ctx, p.from, nil)
+	if err != nil {
+		return false, err
+	}
+	if nonce > p.Nonce() {
+		// The nonce is used; make sure our transaction was not mined in between.
+		_, err := p.client.TransactionReceipt(ctx, p.Hash())
+		switch {
+		case err == ethereum.NotFound:
+			return true, ErrTxReplaced
+		case err != nil:
+			return false, err
+		}
+		return false, nil
+	}
+
+	_, _, err = p.client.TransactionByHash(ctx, p.Hash())
+	switch {
+	case err == ethereum.NotFound:
+		*misses++
+		if *misses >= droppedAfterMisses {
+			return true, ErrTxDropped
+		}
+		return false, nil
+	case err != nil:
+		return false, err
+	}
+	*misses = 0
+	return false, nil
+}
+
+// signTx signs tx with the client's signer.
+func (c *ClientTokenEth) signTx(ctx context.Context, tx *types.Transaction, key *ecdsa.PrivateKey) (*types.Transaction, error) {
+	signer, err := c.Signer(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return types.SignTx(tx, signer, key)
+}
+
+// signAndSend signs tx with the client's signer and broadcasts it.
+func (c *ClientTokenEth) signAndSend(ctx context.Context, tx *types.Transaction, key *ecdsa.PrivateKey) (*PendingTransaction, error) {
+	from, err := privateKeyToPubAddress(key)
+	if err != nil {
+		return nil, err
+	}
+	signTx, err := c.signTx(ctx, tx, key)
+	if err != nil {
+		return nil, err
+	}
+	if err := c.SendTransaction(ctx, signTx); err != nil && !isKnownTransaction(err) {
+		return nil, err
+	}
+	return c.NewPendingTransaction(signTx, *from), nil
+}
+
+// sendWithNextNonce reserves the next nonce of the key's account, builds the
+// transaction for it, then signs and broadcasts it. Nonces of transactions
+// that never reached the pool are released; a stale nonce resyncs the account
+// and the send is retried once with a fresh one. A transaction the node already
+// knows was broadcast before and is returned as sent.
+func (c *ClientTokenEth) sendWithNextNonce(ctx context.Context, key *ecdsa.PrivateKey, build func(nonce uint64) (*types.Transaction, error)) (*PendingTransaction, error) {
+	from, err := privateKeyToPubAddress(key)
+	if err != nil {
+		return nil, err
+	}
+	for attempt := 0; ; attempt++ {
+		nonce, err := c.nextNonce(ctx, *from)
+		if err != nil {
+			return nil, err
+		}
+		tx, err := build(nonce)
+		if err == nil {
+			tx, err = c.signTx(ctx, tx, key)
+		}
+		if err != nil {
+			c.releaseNonce(*from, nonce)
+			return nil, err
+		}
+		err = c.SendTransaction(ctx, tx)
+		if err == nil || isKnownTransaction(err) {
+			return c.NewPendingTransaction(tx, *from), nil
+		}
+
+		if IsNonceError(err) {
+			c.resyncNonce(ctx, *from)
+			if attempt == 0 {
+				continue
+			}
+			return nil, err
+		}
+		if _, rejected := err.(ethrpc.Error); rejected {
+			// The node refused the transaction, the nonce is still free.
+			c.releaseNonce(*from, nonce)
+		} else {
+			// Transport failure: the transaction may have reached the node.
+			c.resyncNonce(ctx, *from)
+		}
+		return nil, err
+	}
+}
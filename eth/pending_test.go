@@ -0,0 +1,228 @@
+package eth
+
+import (
+	"context"
+	"errors"
+	"math/big"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/crypto"
+	ethrpc "github.com/ethereum/go-ethereum/rpc"
+)
+
+// pendingService serves the head, receipt, account nonce and pool lookup of a
+// single transaction. It has no newHeads subscription, so Wait has to poll.
+type pendingService struct {
+	mu      sync.Mutex
+	head    int64
+	nonce   uint64
+	pooled  *types.Transaction // answer of eth_getTransactionByHash
+	receipt func() (*types.Receipt, error)
+}
+
+func (s *pendingService) GetBlockByNumber(number ethrpc.BlockNumber, full bool) (*types.Header, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return testHeader(s.head, nil), nil
+}
+
+func (s *pendingService) GetTransactionReceipt(hash common.Hash) (*types.Receipt, error) {
+	s.mu.Lock()
+	receipt := s.receipt
+	s.mu.Unlock()
+	if receipt == nil {
+		return nil, nil
+	}
+	return receipt()
+}
+
+func (s *pendingService) GetTransactionCount(account common.Address, block string) hexutil.Uint64 {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return hexutil.Uint64(s.nonce)
+}
+
+func (s *pendingService) GetTransactionByHash(hash common.Hash) (*types.Transaction, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.pooled, nil
+}
+
+// setHead moves the head of the service to number.
+func (s *pendingService) setHead(number int64) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.head = number
+}
+
+// setReceipt makes the service answer eth_getTransactionReceipt with r.
+func (s *pendingService) setReceipt(r *types.Receipt) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.receipt = func() (*types.Receipt, error) { return r, nil }
+}
+
+// headService adds a newHeads subscription fed by heads to pendingService.
+type headService struct {
+	*pendingService
+	heads chan *types.Header
+}
+
+func (s *headService) NewHeads(ctx context.Context) (*ethrpc.Subscription, error) {
+	notifier, ok := ethrpc.NotifierFromContext(ctx)
+	if !ok {
+		return nil, ethrpc.ErrNotificationsUnsupported
+	}
+	sub := notifier.CreateSubscription()
+	go func() {
+		for {
+			select {
+			case h := <-s.heads:
+				notifier.Notify(sub.ID, h)
+			case <-sub.Err():
+				return
+			}
+		}
+	}()
+	return sub, nil
+}
+
+// testPending returns a handle on a transaction with nonce 0 sent to the
+// in-process server of services.
+func testPending(t *testing.T, services map[string]interface{}) (*PendingTransaction, *types.Transaction) {
+	t.Helper()
+	key, _ := crypto.GenerateKey()
+	tx := signedTransfer(t, key, big.NewInt(1337), 0, testWalletA)
+	c := newTestClient(t, services)
+	p := c.NewPendingTransaction(tx, crypto.PubkeyToAddress(key.PublicKey))
+	p.PollInterval = 10 * time.Millisecond
+	return p, tx
+}
+
+func minedReceipt(tx *types.Transaction, number int64, status uint64) *types.Receipt {
+	return &types.Receipt{
+		Status:      status,
+		TxHash:      tx.Hash(),
+		BlockNumber: big.NewInt(number),
+		Logs:        []*types.Log{},
+	}
+}
+
+// waitResult runs Wait in the background.
+func waitResult(ctx context.Context, p *PendingTransaction, confirmations uint64) <-chan error {
+	done := make(chan error, 1)
+	go func() {
+		_, err := p.Wait(ctx, confirmations)
+		done <- err
+	}()
+	return done
+}
+
+func TestPendingConfirmations(t *testing.T) {
+	service := &headService{pendingService: &pendingService{head: 5}, heads: make(chan *types.Header)}
+	p, tx := testPending(t, map[string]interface{}{"eth": service})
+	service.setReceipt(minedReceipt(tx, 5, types.ReceiptStatusSuccessful))
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	done := waitResult(ctx, p, 3)
+
+	// The including block and one more are two confirmations.
+	service.setHead(6)
+	service.heads <- testHeader(6, nil)
+	select {
+	case err := <-done:
+		t.Fatalf("Wait returned after two confirmations: %v", err)
+	case <-time.After(100 * time.Millisecond):
+	}
+
+	service.setHead(7)
+	service.heads <- testHeader(7, nil)
+	if err := <-done; err != nil {
+		t.Fatalf("Wait failed: %v", err)
+	}
+}
+
+func TestPendingFailed(t *testing.T) {
+	service := &pendingService{head: 5}
+	p, tx := testPending(t, map[string]interface{}{"eth": service})
+	service.setReceipt(minedReceipt(tx, 5, types.ReceiptStatusFailed))
+
+	receipt, err := p.Wait(context.Background(), 1)
+	if err != ErrTxFailed {
+		t.Fatalf("Wait error mismatch: have %v, want %v", err, ErrTxFailed)
+	}
+	if receipt == nil || receipt.TxHash != tx.Hash() {
+		t.Fatalf("receipt mismatch: have %+v", receipt)
+	}
+}
+
+func TestPendingReplaced(t *testing.T) {
+	service := &pendingService{head: 5, nonce: 1}
+	p, _ := testPending(t, map[string]interface{}{"eth": service})
+
+	if _, err := p.Wait(context.Background(), 1); err != ErrTxReplaced {
+		t.Fatalf("Wait error mismatch: have %v, want %v", err, ErrTxReplaced)
+	}
+}
+
+func TestPendingReplacedCheckFails(t *testing.T) {
+	service := &pendingService{head: 5, nonce: 1}
+	p, tx := testPending(t, map[string]interface{}{"eth": service})
+
+	// The receipt lookup confirming the replacement fails, and the
+	// transaction turns out to be mined on the next poll.
+	var calls int
+	service.receipt = func() (*types.Receipt, error) {
+		switch calls++; calls {
+		case 1:
+			return nil, nil
+		case 2:
+			return nil, errors.New("connection reset")
+		}
+		return minedReceipt(tx, 5, types.ReceiptStatusSuccessful), nil
+	}
+	if _, err := p.Wait(context.Background(), 1); err != nil {
+		t.Fatalf("Wait failed: %v", err)
+	}
+}
+
+func TestPendingDropped(t *testing.T) {
+	service := &pendingService{head: 5}
+	p, _ := testPending(t, map[string]interface{}{"eth": service})
+
+	start := time.Now()
+	if _, err := p.Wait(context.Background(), 1); err != ErrTxDropped {
+		t.Fatalf("Wait error mismatch: have %v, want %v", err, ErrTxDropped)
+	}
+	// The first miss is immediate, the others take a poll each.
+	if elapsed := time.Since(start); elapsed < (droppedAfterMisses-1)*p.PollInterval {
+		t.Fatalf("dropped after %v, before %d polls", elapsed, droppedAfterMisses)
+	}
+}
+
+func TestPendingPollingFallback(t *testing.T) {
+	service := &pendingService{head: 5}
+	p, tx := testPending(t, map[string]interface{}{"eth": service})
+	service.pooled = tx
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	done := waitResult(ctx, p, 1)
+	select {
+	case err := <-done:
+		t.Fatalf("Wait returned before the receipt: %v", err)
+	case <-time.After(50 * time.Millisecond):
+	}
+
+	// Without newHeads notifications only polling can pick up the receipt.
+	service.setReceipt(minedReceipt(tx, 5, types.ReceiptStatusSuccessful))
+	if err := <-done; err != nil {
+		t.Fatalf("Wait failed: %v", err)
+	}
+}
@@ -0,0 +1,80 @@
+package eth
+
+import (
+	"context"
+	"math/big"
+
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/accounts/abi/bind"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+)
+
+// blockPin makes a series of state reads against one block. Reads go by block
+// hash (EIP-1898) so a reorg between them cannot mix states; nodes that do not
+// accept block hashes are read by number instead.
+type blockPin struct {
+	ctx    context.Context
+	number *big.Int
+	hash   common.Hash
+	byHash bool
+}
+
+func newBlockPin(ctx context.Context, header *types.Header) *blockPin {
+	return &blockPin{
+		ctx:    ctx,
+		number: header.Number,
+		hash:   header.Hash(),
+		byHash: true,
+	}
+}
+
+// call runs fn with call options pinned to the block.
+func (p *blockPin) call(fn func(opts *bind.CallOpts) error) error {
+	if p.byHash {
+		err := fn(&bind.CallOpts{Context: p.ctx, BlockHash: p.hash})
+		if err == nil {
+			return nil
+		}
+		if fn(&bind.CallOpts{Context: p.ctx, BlockNumber: p.number}) != nil {
+			// Failing by number too, the call itself is at fault.
+			return err
+		}
+		log.Debug("Node rejects calls by block hash, pinning by number", "block", p.number)
+		p.byHash = false
+		return nil
+	}
+	return fn(&bind.CallOpts{Context: p.ctx, BlockNumber: p.number})
+}
+
+// pinnedBalanceAt returns the ether balance of account at the pinned block.
+func (c *ClientTokenEth) pinnedBalanceAt(ctx context.Context, account common.Address, pin *blockPin) (*big.Int, error) {
+	if pin.byHash {
+		balance, err := c.BalanceAtHash(ctx, account, pin.hash)
+		if err == nil {
+			return balance, nil
+		}
+		log.Debug("Node rejects eth_getBalance by block hash, pinning by number", "block", pin.number, "err", err)
+		pin.byHash = false
+	}
+	return c.BalanceAt(ctx, account, pin.number)
+}
+
+// pinnedCallContract executes msg at the pinned block.
+func (c *ClientTokenEth) pinnedCallContract(ctx context.Context, msg ethereum.CallMsg, pin *blockPin) ([]byte, error) {
+	if pin.byHash {
+		output, err := c.CallContractAtHash(ctx, msg, pin.hash)
+		if err == nil {
+			return output, nil
+		}
+		output, numErr := c.CallContract(ctx, msg, pin.number)
+		if numErr != nil {
+			// Failing by number too, the call itself is at fault.
+			return nil, err
+		}
+		log.Debug("Node rejects eth_call by block hash, pinning by number", "block", pin.number)
+		pin.byHash = false
+		return output, nil
+	}
+	return c.CallContract(ctx, msg, pin.number)
+}
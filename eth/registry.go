@@ -0,0 +1,293 @@
+package eth
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/ethereum/go-ethereum/common"
+	"gopkg.in/yaml.v2"
+)
+
+// MainnetChainID is the chain ID of the Ethereum main network.
+const MainnetChainID = 1
+
+// TokenEntry describes a token known to a TokenRegistry. Decimals is -1 when
+// unknown.
+type TokenEntry struct {
+	ChainID  uint64
+	Address  common.Address
+	Symbol   string
+	Name     string
+	Decimals int64
+}
+
+// TokenRegistry maps token contracts to their metadata, per chain. Addresses
+// are compared in their binary form and symbols case-insensitively; several
+// contracts may share a symbol. It is safe for concurrent use.
+type TokenRegistry struct {
+	mu        sync.RWMutex
+	byAddress map[uint64]map[common.Address]TokenEntry
+	bySymbol  map[uint64]map[string][]common.Address
+}
+
+// NewTokenRegistry creates an empty registry.
+func NewTokenRegistry() *TokenRegistry {
+	return &TokenRegistry{
+		byAddress: make(map[uint64]map[common.Address]TokenEntry),
+		bySymbol:  make(map[uint64]map[string][]common.Address),
+	}
+}
+
+// DefaultTokenRegistry creates a registry holding the built-in mainnet tokens.
+// They are added in symbol order so that lookups return the same order on
+// every run.
+func DefaultTokenRegistry() *TokenRegistry {
+	symbols := make([]string, 0, len(mainnetTokens))
+	for symbol := range mainnetTokens {
+		symbols = append(symbols, symbol)
+	}
+	sort.Strings(symbols)
+
+	r := NewTokenRegistry()
+	for _, symbol := range symbols {
+		r.Add(TokenEntry{
+			ChainID:  MainnetChainID,
+			Address:  common.HexToAddress(mainnetTokens[symbol]),
+			Symbol:   symbol,
+			Decimals: -1,
+		})
+	}
+	return r
+}
+
+// Add registers a token, replacing any entry with the same chain and address.
+func (r *TokenRegistry) Add(entry TokenEntry) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.remove(entry.ChainID, entry.Address)
+	if r.byAddress[entry.ChainID] == nil {
+		r.byAddress[entry.ChainID] = make(map[common.Address]TokenEntry)
+		r.bySymbol[entry.ChainID] = make(map[string][]common.Address)
+	}
+	r.byAddress[entry.ChainID][entry.Address] = entry
+	key := strings.ToUpper(entry.Symbol)
+	r.bySymbol[entry.ChainID][key] = append(r.bySymbol[entry.ChainID][key], entry.Address)
+}
+
+// Remove unregisters the token at address on the given chain.
+func (r *TokenRegistry) Remove(chainID uint64, address common.Address) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.remove(chainID, address)
+}
+
+func (r *TokenRegistry) remove(chainID uint64, address common.Address) {
+	entry, ok := r.byAddress[chainID][address]
+	if !ok {
+		return
+	}
+	delete(r.byAddress[chainID], address)
+
+	key := strings.ToUpper(entry.Symbol)
+	addrs := r.bySymbol[chainID][key]
+	for i, addr := range addrs {
+		if addr == address {
+			addrs = append(addrs[:i:i], addrs[i+1:]...)
+			break
+		}
+	}
+	if len(addrs) == 0 {
+		delete(r.bySymbol[chainID], key)
+	} else {
+		r.bySymbol[chainID][key] = addrs
+	}
+}
+
+// ByAddress returns the token at address on the given chain.
+func (r *TokenRegistry) ByAddress(chainID uint64, address common.Address) (TokenEntry, bool) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	entry, ok := r.byAddress[chainID][address]
+	return entry, ok
+}
+
+// BySymbol returns the tokens with the given symbol on the given chain, in the
+// order they were registered.
+func (r *TokenRegistry) BySymbol(chainID uint64, symbol string) []TokenEntry {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	var entries []TokenEntry
+	for _, addr := range r.bySymbol[chainID][strings.ToUpper(symbol)] {
+		entries = append(entries, r.byAddress[chainID][addr])
+	}
+	return entries
+}
+
+// Tokens returns every token of the given chain, sorted by symbol.
+func (r *TokenRegistry) Tokens(chainID uint64) []TokenEntry {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	entries := make([]TokenEntry, 0, len(r.byAddress[chainID]))
+	for _, entry := range r.byAddress[chainID] {
+		entries = append(entries, entry)
+	}
+	sort.Slice(entries, func(i, j int) bool {
+		if entries[i].Symbol != entries[j].Symbol {
+			return entries[i].Symbol < entries[j].Symbol
+		}
+		return entries[i].Address.Hex() < entries[j].Address.Hex()
+	})
+	return entries
+}
+
+// tokenListEntry is a token as found in a token list file.
+type tokenListEntry struct {
+	ChainID  uint64 `json:"chainId" yaml:"chainId"`
+	Address  string `json:"address" yaml:"address"`
+	Symbol   string `json:"symbol" yaml:"symbol"`
+	Name     string `json:"name" yaml:"name"`
+	Decimals *int64 `json:"decimals" yaml:"decimals"`
+}
+
+// tokenList is the Uniswap token list layout (https://tokenlists.org).
+type tokenList struct {
+	Name   string           `json:"name" yaml:"name"`
+	Tokens []tokenListEntry `json:"tokens" yaml:"tokens"`
+}
+
+// LoadJSON adds the tokens of a JSON token list, either in the Uniswap token
+// list format or as a bare array of tokens.
+func (r *TokenRegistry) LoadJSON(in io.Reader) error {
+	data, err := io.ReadAll(in)
+	if err != nil {
+		return err
+	}
+	var entries []tokenListEntry
+	if strings.HasPrefix(strings.TrimSpace(string(data)), "[") {
+		err = json.Unmarshal(data, &entries)
+	} else {
+		var list tokenList
+		err = json.Unmarshal(data, &list)
+		entries = list.Tokens
+	}
+	if err != nil {
+		return err
+	}
+	return r.load(entries)
+}
+
+// LoadYAML adds the tokens of a YAML token list, using the same layouts as
+// LoadJSON.
+func (r *TokenRegistry) LoadYAML(in io.Reader) error {
+	data, err := io.ReadAll(in)
+	if err != nil {
+		return err
+	}
+	var entries []tokenListEntry
+	if err := yaml.Unmarshal(data, &entries); err != nil {
+		var list tokenList
+		if err := yaml.Unmarshal(data, &list); err != nil {
+			return err
+		}
+		entries = list.Tokens
+	}
+	return r.load(entries)
+}
+
+// LoadFile adds the tokens of a token list file, picking the format from the
+// file extension.
+func (r *TokenRegistry) LoadFile(path string) error {
+	f, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".yaml", ".yml":
+		return r.LoadYAML(f)
+	default:
+		return r.LoadJSON(f)
+	}
+}
+
+// load validates all entries before registering any of them.
+func (r *TokenRegistry) load(list []tokenListEntry) error {
+	entries := make([]TokenEntry, len(list))
+	for i, e := range list {
+		if !common.IsHexAddress(e.Address) {
+			return fmt.Errorf("token %d (%s): invalid address %q", i, e.Symbol, e.Address)
+		}
+		entries[i] = TokenEntry{
+			ChainID:  e.ChainID,
+			Address:  common.HexToAddress(e.Address),
+			Symbol:   e.Symbol,
+			Name:     e.Name,
+			Decimals: -1,
+		}
+		if entries[i].ChainID == 0 {
+			entries[i].ChainID = MainnetChainID
+		}
+		if e.Decimals != nil {
+			entries[i].Decimals = *e.Decimals
+		}
+	}
+	for _, entry := range entries {
+		r.Add(entry)
+	}
+	return nil
+}
+
+// SetTokenRegistry replaces the registry used by the client to name tokens.
+func (c *ClientTokenEth) SetTokenRegistry(r *TokenRegistry) {
+	c.tokens = r
+}
+
+// TokenRegistry returns the registry used by the client to name tokens.
+func (c *ClientTokenEth) TokenRegistry() *TokenRegistry {
+	return c.tokens
+}
+
+// registryChainID returns the chain the client's registry lookups are keyed by.
+func (c *ClientTokenEth) registryChainID(ctx context.Context) (uint64, bool) {
+	id, err := c.SignerChainID(ctx)
+	if err != nil {
+		log.Debug("Failed to resolve chain ID for token registry", "err", err)
+		return 0, false
+	}
+	return id.Uint64(), true
+}
+
+// TokenByAddress looks the token contract up in the client's registry.
+func (c *ClientTokenEth) TokenByAddress(ctx context.Context, contract common.Address) (TokenEntry, bool) {
+	chainID, ok := c.registryChainID(ctx)
+	if !ok || c.tokens == nil {
+		return TokenEntry{}, false
+	}
+	return c.tokens.ByAddress(chainID, contract)
+}
+
+// TokensBySymbol looks the symbol up in the client's registry.
+func (c *ClientTokenEth) TokensBySymbol(ctx context.Context, symbol string) []TokenEntry {
+	chainID, ok := c.registryChainID(ctx)
+	if !ok || c.tokens == nil {
+		return nil
+	}
+	return c.tokens.BySymbol(chainID, symbol)
+}
+
+// registrySymbol returns the registered symbol of contract, or "MISSING".
+func (c *ClientTokenEth) registrySymbol(ctx context.Context, contract common.Address) string {
+	if entry, ok := c.TokenByAddress(ctx, contract); ok {
+		return entry.Symbol
+	}
+	return "MISSING"
+}
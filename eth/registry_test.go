@@ -0,0 +1,51 @@
+package eth
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+)
+
+func TestTokenRegistryLoadJSON(t *testing.T) {
+	list := `{
+		"name": "test list",
+		"tokens": [
+			{"chainId": 1, "address": "0xa0b86991c6218b36c1d19d4a2e9eb0ce3606eb48", "symbol": "USDC", "name": "USD Coin", "decimals": 6},
+			{"chainId": 1, "address": "0x0000000000000000000000000000000000000001", "symbol": "usdc", "name": "Fake USD Coin", "decimals": 6},
+			{"chainId": 5, "address": "0x07865c6E87B9F70255377e024ace6630C1Eaa37F", "symbol": "USDC", "name": "USD Coin", "decimals": 6}
+		]
+	}`
+	r := NewTokenRegistry()
+	if err := r.LoadJSON(strings.NewReader(list)); err != nil {
+		t.Fatal(err)
+	}
+
+	usdc := common.HexToAddress("0xA0b86991c6218b36c1d19D4a2e9Eb0cE3606eB48")
+	entry, ok := r.ByAddress(1, usdc)
+	if !ok || entry.Symbol != "USDC" || entry.Decimals != 6 {
+		t.Fatalf("unexpected entry for checksummed address: %+v, %v", entry, ok)
+	}
+	if got := len(r.BySymbol(1, "UsDc")); got != 2 {
+		t.Fatalf("duplicate symbol count mismatch: have %d, want 2", got)
+	}
+	if got := len(r.BySymbol(5, "USDC")); got != 1 {
+		t.Fatalf("chain 5 symbol count mismatch: have %d, want 1", got)
+	}
+
+	r.Remove(1, usdc)
+	if got := r.BySymbol(1, "USDC"); len(got) != 1 || got[0].Name != "Fake USD Coin" {
+		t.Fatalf("unexpected entries after remove: %+v", got)
+	}
+}
+
+func TestTokenRegistryRejectsInvalidAddress(t *testing.T) {
+	r := NewTokenRegistry()
+	err := r.LoadJSON(strings.NewReader(`[{"chainId": 1, "address": "0x1234", "symbol": "BAD"}]`))
+	if err == nil {
+		t.Fatal("expected error for invalid address")
+	}
+	if len(r.Tokens(1)) != 0 {
+		t.Fatal("invalid list must not be partially loaded")
+	}
+}
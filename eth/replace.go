@@ -0,0 +1,226 @@
+package eth
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"errors"
+	"math/big"
+	"sync"
+
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/crypto"
+)
+
+// replacementBump is the minimum price increase, in percent, geth requires to
+// replace a pooled transaction with the same nonce.
+const replacementBump = 10
+
+var (
+	// ErrTxNotPending is returned when a transaction to replace is already mined.
+	ErrTxNotPending = errors.New("transaction is not pending")
+	// ErrNotSender is returned when the key given to replace a transaction is
+	// not the one that signed it.
+	ErrNotSender = errors.New("key does not match the transaction sender")
+)
+
+// Replacement tracks a pending transaction together with the transactions
+// sent to replace it, all sharing one nonce.
+type Replacement struct {
+	client *ClientTokenEth
+
+	mu  sync.Mutex
+	txs []*PendingTransaction
+}
+
+// Transactions returns the competing transactions, the original first.
+func (r *Replacement) Transactions() []*PendingTransaction {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return append([]*PendingTransaction(nil), r.txs...)
+}
+
+// Latest returns the most recently sent transaction.
+func (r *Replacement) Latest() *PendingTransaction {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.txs[len(r.txs)-1]
+}
+
+func (r *Replacement) add(p *PendingTransaction) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.txs = append(r.txs, p)
+}
+
+// Wait blocks until one of the competing transactions is mined and buried
+// under the given number of confirmations, and returns it with its receipt.
+// ErrTxFailed is returned alongside the receipt if the winner reverted; if a
+// transaction unknown to this tracker consumed the nonce, ErrTxReplaced is
+// returned.
+func (r *Replacement) Wait(ctx context.Context, confirmations uint64) (*PendingTransaction, *types.Receipt, error) {
+	latest := r.Latest()
+	receipt, err := latest.Wait(ctx, confirmations)
+	if err != ErrTxReplaced && err != ErrTxDropped {
+		return latest, receipt, err
+	}
+	// The latest transaction lost; one of the earlier ones may have won.
+	for _, p := range r.Transactions() {
+		receipt, err := r.client.TransactionReceipt(ctx, p.Hash())
+		if err == ethereum.NotFound {
+			continue
+		}
+		if err != nil {
+			return nil, nil, err
+		}
+		receipt, err = p.Wait(ctx, confirmations)
+		return p, receipt, err
+	}
+	return nil, nil, err
+}
+
+// SpeedUp re-signs the pending transaction with the given hash at the same
+// nonce and a fee raised by at least the node's replacement threshold, or to
+// the current fee suggestion if that is higher.
+func (c *ClientTokenEth) SpeedUp(ctx context.Context, fromPriv string, hash common.Hash) (*Replacement, error) {
+	return c.replace(ctx, fromPriv, hash, false)
+}
+
+// Cancel replaces the pending transaction with the given hash by a 0-value
+// transfer to the sender itself at the same nonce and a bumped fee.
+func (c *ClientTokenEth) Cancel(ctx context.Context, fromPriv string, hash common.Hash) (*Replacement, error) {
+	return c.replace(ctx, fromPriv, hash, true)
+}
+
+// SpeedUpPending replaces a transaction tracked by Replacement, keeping all
+// the attempts in the same tracker.
+func (c *ClientTokenEth) SpeedUpPending(ctx context.Context, fromPriv string, r *Replacement) error {
+	return c.replaceInto(ctx, fromPriv, r, false)
+}
+
+// CancelPending cancels a transaction tracked by Replacement, keeping all the
+// attempts in the same tracker.
+func (c *ClientTokenEth) CancelPending(ctx context.Context, fromPriv string, r *Replacement) error {
+	return c.replaceInto(ctx, fromPriv, r, true)
+}
+
+func (c *ClientTokenEth) replace(ctx context.Context, fromPriv string, hash common.Hash, cancel bool) (*Replacement, error) {
+	key, from, err := parsePrivateKey(fromPriv)
+	if err != nil {
+		return nil, err
+	}
+	tx, pending, err := c.TransactionByHash(ctx, hash)
+	if err != nil {
+		return nil, err
+	}
+	if !pending {
+		return nil, ErrTxNotPending
+	}
+	signer, err := c.Signer(ctx)
+	if err != nil {
+		return nil, err
+	}
+	sender, err := types.Sender(signer, tx)
+	if err != nil {
+		return nil, err
+	}
+	if sender != from {
+		return nil, ErrNotSender
+	}
+	r := &Replacement{
+		client: c,
+		txs:    []*PendingTransaction{c.NewPendingTransaction(tx, from)},
+	}
+	if err := c.replaceWith(ctx, key, from, r, tx, cancel); err != nil {
+		return nil, err
+	}
+	return r, nil
+}
+
+func (c *ClientTokenEth) replaceInto(ctx context.Context, fromPriv string, r *Replacement, cancel bool) error {
+	key, from, err := parsePrivateKey(fromPriv)
+	if err != nil {
+		return err
+	}
+	latest := r.Latest()
+	if latest.From() != from {
+		return ErrNotSender
+	}
+	return c.replaceWith(ctx, key, from, r, latest.Transaction(), cancel)
+}
+
+func (c *ClientTokenEth) replaceWith(ctx context.Context, key *ecdsa.PrivateKey, from common.Address, r *Replacement, old *types.Transaction, cancel bool) error {
+	fees, err := c.SuggestFees(ctx)
+	if err != nil {
+		return err
+	}
+	fees = bumpFees(old, fees)
+
+	var (
+		to    = old.To()
+		value = old.Value()
+		data  = old.Data()
+		gas   = old.Gas()
+	)
+	if cancel {
+		to, value, data = &from, new(big.Int), nil
+		if gas, err = c.estimateGas(ctx, from, to, value, nil, fees); err != nil {
+			return err
+		}
+	}
+
+	chainID, err := c.SignerChainID(ctx)
+	if err != nil {
+		return err
+	}
+	tx := newTransaction(chainID, old.Nonce(), to, value, gas, fees, data)
+	pending, err := c.signAndSend(ctx, tx, key)
+	if err != nil {
+		return err
+	}
+	r.add(pending)
+	return nil
+}
+
+// bumpFees returns fees for a replacement of old: the suggested fees, raised
+// where needed so every price component beats old's by replacementBump
+// percent. On a London chain a legacy transaction is replaced by a dynamic-fee
+// one, whose tip and fee cap are both compared against the old gas price; a
+// legacy replacement would have to pay the whole fee cap.
+func bumpFees(old *types.Transaction, suggested *Fees) *Fees {
+	if !suggested.IsDynamic() {
+		return &Fees{GasPrice: maxBig(suggested.GasPrice, bumpPrice(old.GasPrice()))}
+	}
+	// GasTipCap and GasFeeCap both return the gas price of a legacy transaction.
+	return &Fees{
+		GasTipCap: maxBig(suggested.GasTipCap, bumpPrice(old.GasTipCap())),
+		GasFeeCap: maxBig(suggested.GasFeeCap, bumpPrice(old.GasFeeCap())),
+	}
+}
+
+// bumpPrice raises price by replacementBump percent, rounding up.
+func bumpPrice(price *big.Int) *big.Int {
+	bumped := new(big.Int).Mul(price, big.NewInt(100+replacementBump))
+	bumped.Add(bumped, big.NewInt(99))
+	return bumped.Div(bumped, big.NewInt(100))
+}
+
+func maxBig(a, b *big.Int) *big.Int {
+	if a.Cmp(b) >= 0 {
+		return new(big.Int).Set(a)
+	}
+	return new(big.Int).Set(b)
+}
+
+func parsePrivateKey(hexkey string) (*ecdsa.PrivateKey, common.Address, error) {
+	key, err := crypto.HexToECDSA(hexkey)
+	if err != nil {
+		return nil, common.Address{}, err
+	}
+	from, err := privateKeyToPubAddress(key)
+	if err != nil {
+		return nil, common.Address{}, err
+	}
+	return key, *from, nil
+}
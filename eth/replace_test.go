@@ -0,0 +1,97 @@
+package eth
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/core/types"
+)
+
+func TestBumpPrice(t *testing.T) {
+	tests := []struct {
+		price, want int64
+	}{
+		{0, 0},
+		{1, 2},
+		{10, 11},
+		{100, 110},
+		{101, 112},
+		{20 * gwei, 22 * gwei},
+	}
+	for _, test := range tests {
+		if have := bumpPrice(big.NewInt(test.price)); have.Int64() != test.want {
+			t.Errorf("bumpPrice(%d) = %v, want %d", test.price, have, test.want)
+		}
+	}
+}
+
+func TestBumpFees(t *testing.T) {
+	var (
+		legacy  = types.NewTx(&types.LegacyTx{GasPrice: big.NewInt(20 * gwei)})
+		dynamic = types.NewTx(&types.DynamicFeeTx{GasTipCap: big.NewInt(2 * gwei), GasFeeCap: big.NewInt(30 * gwei)})
+	)
+	tests := []struct {
+		name      string
+		old       *types.Transaction
+		suggested Fees
+		want      Fees
+	}{
+		{
+			name:      "legacy below threshold",
+			old:       legacy,
+			suggested: Fees{GasPrice: big.NewInt(21 * gwei)},
+			want:      Fees{GasPrice: big.NewInt(22 * gwei)},
+		},
+		{
+			name:      "legacy above threshold",
+			old:       legacy,
+			suggested: Fees{GasPrice: big.NewInt(40 * gwei)},
+			want:      Fees{GasPrice: big.NewInt(40 * gwei)},
+		},
+		{
+			name:      "legacy replaced by dynamic",
+			old:       legacy,
+			suggested: Fees{GasTipCap: big.NewInt(1 * gwei), GasFeeCap: big.NewInt(30 * gwei)},
+			want:      Fees{GasTipCap: big.NewInt(22 * gwei), GasFeeCap: big.NewInt(30 * gwei)},
+		},
+		{
+			name:      "legacy replaced by dynamic, low cap",
+			old:       legacy,
+			suggested: Fees{GasTipCap: big.NewInt(1 * gwei), GasFeeCap: big.NewInt(10 * gwei)},
+			want:      Fees{GasTipCap: big.NewInt(22 * gwei), GasFeeCap: big.NewInt(22 * gwei)},
+		},
+		{
+			name:      "dynamic below threshold",
+			old:       dynamic,
+			suggested: Fees{GasTipCap: big.NewInt(2 * gwei), GasFeeCap: big.NewInt(30 * gwei)},
+			want:      Fees{GasTipCap: big.NewInt(2200000000), GasFeeCap: big.NewInt(33 * gwei)},
+		},
+		{
+			name:      "dynamic tip above threshold",
+			old:       dynamic,
+			suggested: Fees{GasTipCap: big.NewInt(5 * gwei), GasFeeCap: big.NewInt(25 * gwei)},
+			want:      Fees{GasTipCap: big.NewInt(5 * gwei), GasFeeCap: big.NewInt(33 * gwei)},
+		},
+		{
+			name:      "dynamic on legacy pricing",
+			old:       dynamic,
+			suggested: Fees{GasPrice: big.NewInt(10 * gwei)},
+			want:      Fees{GasPrice: big.NewInt(33 * gwei)},
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			suggested := test.suggested
+			have := bumpFees(test.old, &suggested)
+			for _, pair := range [][2]*big.Int{
+				{have.GasPrice, test.want.GasPrice},
+				{have.GasTipCap, test.want.GasTipCap},
+				{have.GasFeeCap, test.want.GasFeeCap},
+			} {
+				if (pair[0] == nil) != (pair[1] == nil) || (pair[0] != nil && pair[0].Cmp(pair[1]) != 0) {
+					t.Fatalf("fees mismatch: have %+v, want %+v", have, test.want)
+				}
+			}
+		})
+	}
+}
@@ -30,6 +30,11 @@ type PrivateAdmin interface {
 	AddPeer(ctx context.Context, nodeURL string) (bool, error)
 	// RemovePeer disconnects from a remote node if the connection exists
 	RemovePeer(ctx context.Context, nodeURL string) (bool, error)
+	// AddTrustedPeer allows a remote node to always connect, even if slots are full.
+	AddTrustedPeer(ctx context.Context, nodeURL string) (bool, error)
+	// RemoveTrustedPeer removes a remote node from the trusted peer set, but it
+	// does not disconnect it automatically.
+	RemoveTrustedPeer(ctx context.Context, nodeURL string) (bool, error)
 	// ImportChain imports a blockchain from a local file.
 	ImportChain(ctx context.Context, file string) (bool, error)
 	// ExportChain exports the current blockchain into a local file.
@@ -74,6 +79,27 @@ func (pri *privateAdmin) RemovePeer(ctx context.Context, nodeURL string) (bool,
 	return r, nil
 }
 
+// AddTrustedPeer allows a remote node to always connect, even if slots are full.
+func (pri *privateAdmin) AddTrustedPeer(ctx context.Context, nodeURL string) (bool, error) {
+	var r bool
+	err := pri.client.CallContext(ctx, &r, "admin_addTrustedPeer", nodeURL)
+	if err != nil {
+		return false, err
+	}
+	return r, nil
+}
+
+// RemoveTrustedPeer removes a remote node from the trusted peer set, but it
+// does not disconnect it automatically.
+func (pri *privateAdmin) RemoveTrustedPeer(ctx context.Context, nodeURL string) (bool, error) {
+	var r bool
+	err := pri.client.CallContext(ctx, &r, "admin_removeTrustedPeer", nodeURL)
+	if err != nil {
+		return false, err
+	}
+	return r, nil
+}
+
 // ImportChain imports a blockchain from a local file.
 func (pri *privateAdmin) ImportChain(ctx context.Context, file string) (bool, error) {
 	var r bool
@@ -0,0 +1,70 @@
+package rpc
+
+import (
+	"context"
+	"testing"
+)
+
+func TestAdminWire(t *testing.T) {
+	client, stub := newRPCStub(t)
+	a := NewAdmin(client)
+
+	const enode = "enode://6f8a80d14311c39f35f516fa664deaaaa13e85b2f7493f37f6144d86991ec012937307647bd3b9a82abe2974e1407241d54947bbb39763a4cac9f77166ad92a0@10.3.58.6:30303"
+	stub.run(t, []wireCase{
+		// PrivateAdmin
+		{"AddPeer", func(ctx context.Context) error {
+			_, err := a.AddPeer(ctx, enode)
+			return err
+		}, "admin_addPeer", `["` + enode + `"]`, `true`},
+		{"RemovePeer", func(ctx context.Context) error {
+			_, err := a.RemovePeer(ctx, enode)
+			return err
+		}, "admin_removePeer", `["` + enode + `"]`, `true`},
+		{"AddTrustedPeer", func(ctx context.Context) error {
+			_, err := a.AddTrustedPeer(ctx, enode)
+			return err
+		}, "admin_addTrustedPeer", `["` + enode + `"]`, `true`},
+		{"RemoveTrustedPeer", func(ctx context.Context) error {
+			_, err := a.RemoveTrustedPeer(ctx, enode)
+			return err
+		}, "admin_removeTrustedPeer", `["` + enode + `"]`, `true`},
+		{"ImportChain", func(ctx context.Context) error {
+			_, err := a.ImportChain(ctx, "/tmp/chain.rlp")
+			return err
+		}, "admin_importChain", `["/tmp/chain.rlp"]`, `true`},
+		{"ExportChain", func(ctx context.Context) error {
+			_, err := a.ExportChain(ctx, "/tmp/chain.rlp")
+			return err
+		}, "admin_exportChain", `["/tmp/chain.rlp"]`, `true`},
+		{"StartRPC", func(ctx context.Context) error {
+			_, err := a.StartRPC(ctx, "localhost", 8545, "*", "eth,net")
+			return err
+		}, "admin_startRPC", `["localhost",8545,"*","eth,net"]`, `true`},
+		{"StopRPC", func(ctx context.Context) error {
+			_, err := a.StopRPC(ctx)
+			return err
+		}, "admin_stopRPC", `[]`, `true`},
+		{"StartWS", func(ctx context.Context) error {
+			_, err := a.StartWS(ctx, "localhost", 8546, "*", "eth")
+			return err
+		}, "admin_startWS", `["localhost",8546,"*","eth"]`, `true`},
+		{"StopWS", func(ctx context.Context) error {
+			_, err := a.StopWS(ctx)
+			return err
+		}, "admin_stopWS", `[]`, `true`},
+
+		// PublicAdmin
+		{"Peers", func(ctx context.Context) error {
+			_, err := a.Peers(ctx)
+			return err
+		}, "admin_peers", `[]`, `[]`},
+		{"NodeInfo", func(ctx context.Context) error {
+			_, err := a.NodeInfo(ctx)
+			return err
+		}, "admin_nodeInfo", `[]`, `null`},
+		{"Datadir", func(ctx context.Context) error {
+			_, err := a.Datadir(ctx)
+			return err
+		}, "admin_datadir", `[]`, `"/data/geth"`},
+	})
+}
@@ -2,13 +2,15 @@ package rpc
 
 import (
 	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
 	"math/big"
 
+	"github.com/ethereum/go-ethereum"
 	"github.com/ethereum/go-ethereum/common"
 	"github.com/ethereum/go-ethereum/common/hexutil"
 	"github.com/ethereum/go-ethereum/core/types"
-	"github.com/ethereum/go-ethereum/eth/downloader"
-	"github.com/ethereum/go-ethereum/eth/filters"
 	ethrpc "github.com/ethereum/go-ethereum/rpc"
 )
 
@@ -69,6 +71,8 @@ type RPCTransaction struct {
 	From             common.Address  `json:"from"`
 	Gas              *hexutil.Big    `json:"gas"`
 	GasPrice         *hexutil.Big    `json:"gasPrice"`
+	GasFeeCap        *hexutil.Big    `json:"maxFeePerGas,omitempty"`
+	GasTipCap        *hexutil.Big    `json:"maxPriorityFeePerGas,omitempty"`
 	Hash             common.Hash     `json:"hash"`
 	Input            hexutil.Bytes   `json:"input"`
 	Nonce            hexutil.Uint64  `json:"nonce"`
@@ -172,7 +176,7 @@ func (pub *publicTransactionPool) GetTransactionByBlockNumberAndIndex(ctx contex
 // GetTransactionByBlockHashAndIndex returns the transaction for the given block hash and index.
 func (pub *publicTransactionPool) GetTransactionByBlockHashAndIndex(ctx context.Context, blockHash common.Hash, index hexutil.Uint) (*RPCTransaction, error) {
 	var r *RPCTransaction
-	err := pub.client.CallContext(ctx, &r, "eth_getTransactionByBlockNumberAndIndex", blockHash, index)
+	err := pub.client.CallContext(ctx, &r, "eth_getTransactionByBlockHashAndIndex", blockHash, index)
 	if err != nil {
 		return nil, err
 	}
@@ -363,12 +367,12 @@ func NewPublicEthereum(client *ethrpc.Client) PublicEthereum {
 
 // GasPrice returns a suggestion for a gas price.
 func (pub *publicEthereum) GasPrice(ctx context.Context) (*big.Int, error) {
-	var r *big.Int
+	var r hexutil.Big
 	err := pub.client.CallContext(ctx, &r, "eth_gasPrice")
 	if err != nil {
-		return r, err
+		return nil, err
 	}
-	return r, nil
+	return (*big.Int)(&r), nil
 
 }
 
@@ -498,31 +502,31 @@ func NewPublicBlockChain(client *ethrpc.Client) PublicBlockChain {
 
 // BlockNumber returns the block number of the chain head.
 func (pub *publicBlockChain) BlockNumber(ctx context.Context) (*big.Int, error) {
-	var r *big.Int
+	var r hexutil.Big
 	err := pub.client.CallContext(ctx, &r, "eth_blockNumber")
 	if err != nil {
-		return r, err
+		return nil, err
 	}
-	return r, nil
+	return (*big.Int)(&r), nil
 }
 
 // GetBalance returns the amount of wei for the given address in the state of the
 // given block number. The rpc.LatestBlockNumber and rpc.PendingBlockNumber meta
 // block numbers are also allowed.
 func (pub *publicBlockChain) GetBalance(ctx context.Context, address common.Address, blockNr string) (*big.Int, error) {
-	var r *big.Int
-	err := pub.client.CallContext(ctx, &r, "eth_getBalance")
+	var r hexutil.Big
+	err := pub.client.CallContext(ctx, &r, "eth_getBalance", address, blockNr)
 	if err != nil {
-		return r, err
+		return nil, err
 	}
-	return r, nil
+	return (*big.Int)(&r), nil
 }
 
 // GetBlockByNumber returns the requested block. When blockNr is -1 the chain head is returned. When fullTx is true all
 // transactions in the block are returned in full detail, otherwise only the transaction hash is returned.
 func (pub *publicBlockChain) GetBlockByNumber(ctx context.Context, blockNr string, fullTx bool) (map[string]interface{}, error) {
 	var r map[string]interface{}
-	err := pub.client.CallContext(ctx, &r, "eth_getBlockByNumber")
+	err := pub.client.CallContext(ctx, &r, "eth_getBlockByNumber", blockNr, fullTx)
 	if err != nil {
 		return r, err
 	}
@@ -533,7 +537,7 @@ func (pub *publicBlockChain) GetBlockByNumber(ctx context.Context, blockNr strin
 // detail, otherwise only the transaction hash is returned.
 func (pub *publicBlockChain) GetBlockByHash(ctx context.Context, blockHash common.Hash, fullTx bool) (map[string]interface{}, error) {
 	var r map[string]interface{}
-	err := pub.client.CallContext(ctx, &r, "eth_getBlockByHash")
+	err := pub.client.CallContext(ctx, &r, "eth_getBlockByHash", blockHash, fullTx)
 	if err != nil {
 		return r, err
 	}
@@ -544,7 +548,7 @@ func (pub *publicBlockChain) GetBlockByHash(ctx context.Context, blockHash commo
 // all transactions in the block are returned in full detail, otherwise only the transaction hash is returned.
 func (pub *publicBlockChain) GetUncleByBlockNumberAndIndex(ctx context.Context, blockNr string, index hexutil.Uint) (map[string]interface{}, error) {
 	var r map[string]interface{}
-	err := pub.client.CallContext(ctx, &r, "eth_getUncleByBlockNumberAndIndex")
+	err := pub.client.CallContext(ctx, &r, "eth_getUncleByBlockNumberAndIndex", blockNr, index)
 	if err != nil {
 		return r, err
 	}
@@ -555,7 +559,7 @@ func (pub *publicBlockChain) GetUncleByBlockNumberAndIndex(ctx context.Context,
 // all transactions in the block are returned in full detail, otherwise only the transaction hash is returned.
 func (pub *publicBlockChain) GetUncleByBlockHashAndIndex(ctx context.Context, blockHash common.Hash, index hexutil.Uint) (map[string]interface{}, error) {
 	var r map[string]interface{}
-	err := pub.client.CallContext(ctx, &r, "eth_getUncleByBlockHashAndIndex")
+	err := pub.client.CallContext(ctx, &r, "eth_getUncleByBlockHashAndIndex", blockHash, index)
 	if err != nil {
 		return r, err
 	}
@@ -565,7 +569,7 @@ func (pub *publicBlockChain) GetUncleByBlockHashAndIndex(ctx context.Context, bl
 // GetUncleCountByBlockNumber returns number of uncles in the block for the given block number
 func (pub *publicBlockChain) GetUncleCountByBlockNumber(ctx context.Context, blockNr string) (*hexutil.Uint, error) {
 	var r *hexutil.Uint
-	err := pub.client.CallContext(ctx, &r, "eth_getUncleCountByBlockNumber")
+	err := pub.client.CallContext(ctx, &r, "eth_getUncleCountByBlockNumber", blockNr)
 	if err != nil {
 		return r, err
 	}
@@ -575,7 +579,7 @@ func (pub *publicBlockChain) GetUncleCountByBlockNumber(ctx context.Context, blo
 // GetUncleCountByBlockHash returns number of uncles in the block for the given block hash
 func (pub *publicBlockChain) GetUncleCountByBlockHash(ctx context.Context, blockHash common.Hash) (*hexutil.Uint, error) {
 	var r *hexutil.Uint
-	err := pub.client.CallContext(ctx, &r, "eth_getUncleCountByBlockHash")
+	err := pub.client.CallContext(ctx, &r, "eth_getUncleCountByBlockHash", blockHash)
 	if err != nil {
 		return r, err
 	}
@@ -585,7 +589,7 @@ func (pub *publicBlockChain) GetUncleCountByBlockHash(ctx context.Context, block
 // GetCode returns the code stored at the given address in the state for the given block number.
 func (pub *publicBlockChain) GetCode(ctx context.Context, address common.Address, blockNr string) (hexutil.Bytes, error) {
 	var r hexutil.Bytes
-	err := pub.client.CallContext(ctx, &r, "eth_getCode")
+	err := pub.client.CallContext(ctx, &r, "eth_getCode", address, blockNr)
 	if err != nil {
 		return r, err
 	}
@@ -597,7 +601,7 @@ func (pub *publicBlockChain) GetCode(ctx context.Context, address common.Address
 // numbers are also allowed.
 func (pub *publicBlockChain) GetStorageAt(ctx context.Context, address common.Address, key string, blockNr string) (hexutil.Bytes, error) {
 	var r hexutil.Bytes
-	err := pub.client.CallContext(ctx, &r, "eth_getStorageAt")
+	err := pub.client.CallContext(ctx, &r, "eth_getStorageAt", address, key, blockNr)
 	if err != nil {
 		return r, err
 	}
@@ -608,7 +612,7 @@ func (pub *publicBlockChain) GetStorageAt(ctx context.Context, address common.Ad
 // It doesn't make and changes in the state/blockchain and is useful to execute and retrieve values.
 func (pub *publicBlockChain) Call(ctx context.Context, args CallArgs, blockNr string) (hexutil.Bytes, error) {
 	var r hexutil.Bytes
-	err := pub.client.CallContext(ctx, &r, "eth_call")
+	err := pub.client.CallContext(ctx, &r, "eth_call", args, blockNr)
 	if err != nil {
 		return r, err
 	}
@@ -619,7 +623,7 @@ func (pub *publicBlockChain) Call(ctx context.Context, args CallArgs, blockNr st
 // given transaction against the current pending block.
 func (pub *publicBlockChain) EstimateGas(ctx context.Context, args CallArgs) (*hexutil.Big, error) {
 	var r *hexutil.Big
-	err := pub.client.CallContext(ctx, &r, "eth_estimateGas")
+	err := pub.client.CallContext(ctx, &r, "eth_estimateGas", args)
 	if err != nil {
 		return r, err
 	}
@@ -695,7 +699,7 @@ func (pub *publicMiner) Mining(ctx context.Context) (bool, error) {
 // accepted. Note, this is not an indication if the provided work was valid!
 func (pub *publicMiner) SubmitWork(ctx context.Context, nonce types.BlockNonce, solution, digest common.Hash) (bool, error) {
 	var r bool
-	err := pub.client.CallContext(ctx, &r, "eth_submitWork")
+	err := pub.client.CallContext(ctx, &r, "eth_submitWork", nonce, solution, digest)
 	if err != nil {
 		return r, err
 	}
@@ -720,21 +724,78 @@ func (pub *publicMiner) GetWork(ctx context.Context) ([3]string, error) {
 // must be unique between nodes.
 func (pub *publicMiner) SubmitHashrate(ctx context.Context, hashrate hexutil.Uint64, id common.Hash) (bool, error) {
 	var r bool
-	err := pub.client.CallContext(ctx, &r, "eth_submitHashrate")
+	err := pub.client.CallContext(ctx, &r, "eth_submitHashrate", hashrate, id)
 	if err != nil {
 		return r, err
 	}
 	return r, nil
 }
 
-type PublicDownloader interface {
-	// Duplicate function with PublicEthereum
-	// Syncing provides information when this nodes starts synchronising with the Ethereum network and when it's finished.
-	// Syncing(ctx context.Context) (*ethrpc.Subscription, error)
+// SyncProgress is the synchronisation progress reported by the node.
+// PulledStates and KnownStates are only meaningful during fast sync.
+type SyncProgress struct {
+	StartingBlock uint64
+	CurrentBlock  uint64
+	HighestBlock  uint64
+	PulledStates  uint64
+	KnownStates   uint64
+}
+
+// SyncStatus is a notification of the syncing subscription. Progress is only
+// set while Syncing is true.
+type SyncStatus struct {
+	Syncing  bool
+	Progress SyncProgress
+}
+
+// quantity decodes a number sent either as a hex string or as a JSON number.
+type quantity uint64
+
+func (q *quantity) UnmarshalJSON(input []byte) error {
+	if len(input) > 0 && input[0] == '"' {
+		return (*hexutil.Uint64)(q).UnmarshalJSON(input)
+	}
+	return json.Unmarshal(input, (*uint64)(q))
+}
+
+// UnmarshalJSON decodes both the bare boolean and the object form of the
+// syncing notification.
+func (s *SyncStatus) UnmarshalJSON(input []byte) error {
+	var syncing bool
+	if err := json.Unmarshal(input, &syncing); err == nil {
+		*s = SyncStatus{Syncing: syncing}
+		return nil
+	}
+	var dec struct {
+		Syncing bool `json:"syncing"`
+		Status  *struct {
+			StartingBlock quantity `json:"startingBlock"`
+			CurrentBlock  quantity `json:"currentBlock"`
+			HighestBlock  quantity `json:"highestBlock"`
+			PulledStates  quantity `json:"pulledStates"`
+			KnownStates   quantity `json:"knownStates"`
+		} `json:"status"`
+	}
+	if err := json.Unmarshal(input, &dec); err != nil {
+		return err
+	}
+	*s = SyncStatus{Syncing: dec.Syncing}
+	if dec.Status != nil {
+		s.Progress = SyncProgress{
+			StartingBlock: uint64(dec.Status.StartingBlock),
+			CurrentBlock:  uint64(dec.Status.CurrentBlock),
+			HighestBlock:  uint64(dec.Status.HighestBlock),
+			PulledStates:  uint64(dec.Status.PulledStates),
+			KnownStates:   uint64(dec.Status.KnownStates),
+		}
+	}
+	return nil
+}
 
+type PublicDownloader interface {
 	// SubscribeSyncStatus creates a subscription that will broadcast new synchronisation updates.
-	// The given channel must receive interface values, the result can either
-	SubscribeSyncStatus(ctx context.Context, status chan interface{}) (*downloader.SyncStatusSubscription, error)
+	// A status with Syncing false is sent when the node stops synchronising.
+	SubscribeSyncStatus(ctx context.Context, ch chan<- *SyncStatus) (*ethrpc.ClientSubscription, error)
 }
 
 type publicDownloader struct {
@@ -747,25 +808,10 @@ func NewPublicDownloader(client *ethrpc.Client) PublicDownloader {
 	}
 }
 
-// // Syncing provides information when this nodes starts synchronising with the Ethereum network and when it's finished.
-// func (pub *publicDownloader) Syncing(ctx context.Context) (*ethrpc.Subscription, error) {
-// 	var r *ethrpc.Subscription
-// 	err := pub.client.CallContext(ctx, &r, "eth_syncing")
-// 	if err != nil {
-// 		return r, err
-// 	}
-// 	return r, nil
-// }
-
 // SubscribeSyncStatus creates a subscription that will broadcast new synchronisation updates.
-// The given channel must receive interface values, the result can either
-func (pub *publicDownloader) SubscribeSyncStatus(ctx context.Context, status chan interface{}) (*downloader.SyncStatusSubscription, error) {
-	var r *downloader.SyncStatusSubscription
-	err := pub.client.CallContext(ctx, &r, "eth_subscribeSyncStatus")
-	if err != nil {
-		return r, err
-	}
-	return r, nil
+// A status with Syncing false is sent when the node stops synchronising.
+func (pub *publicDownloader) SubscribeSyncStatus(ctx context.Context, ch chan<- *SyncStatus) (*ethrpc.ClientSubscription, error) {
+	return pub.client.EthSubscribe(ctx, ch, "syncing")
 }
 
 type PublicFilter interface {
@@ -780,7 +826,7 @@ type PublicFilter interface {
 
 	// NewPendingTransactions creates a subscription that is triggered each time a transaction
 	// enters the transaction pool and was signed from one of the transactions this nodes manages.
-	NewPendingTransactions(ctx context.Context) (*ethrpc.Subscription, error)
+	NewPendingTransactions(ctx context.Context, ch chan<- common.Hash) (*ethrpc.ClientSubscription, error)
 
 	// NewBlockFilter creates a filter that fetches blocks that are imported into the chain.
 	// It is part of the filter package since polling goes with eth_getFilterChanges.
@@ -789,10 +835,36 @@ type PublicFilter interface {
 	NewBlockFilter(ctx context.Context) (ethrpc.ID, error)
 
 	// NewHeads send a notification each time a new (header) block is appended to the chain.
-	NewHeads(ctx context.Context) (*ethrpc.Subscription, error)
+	NewHeads(ctx context.Context, ch chan<- *types.Header) (*ethrpc.ClientSubscription, error)
 
 	// Logs creates a subscription that fires for all new log that match the given filter criteria.
-	Logs(ctx context.Context, crit filters.FilterCriteria) (*ethrpc.Subscription, error)
+	Logs(ctx context.Context, crit ethereum.FilterQuery, ch chan<- types.Log) (*ethrpc.ClientSubscription, error)
+
+	// NewFilter creates a new filter for logs matching the given criteria.
+	//
+	// https://github.com/ethereum/wiki/wiki/JSON-RPC#eth_newfilter
+	NewFilter(ctx context.Context, crit ethereum.FilterQuery) (ethrpc.ID, error)
+
+	// GetFilterChanges returns the logs of a log filter since the last poll.
+	//
+	// https://github.com/ethereum/wiki/wiki/JSON-RPC#eth_getfilterchanges
+	GetFilterChanges(ctx context.Context, id ethrpc.ID) ([]types.Log, error)
+
+	// GetFilterHashChanges returns the block or transaction hashes of a block or
+	// pending transaction filter since the last poll.
+	//
+	// https://github.com/ethereum/wiki/wiki/JSON-RPC#eth_getfilterchanges
+	GetFilterHashChanges(ctx context.Context, id ethrpc.ID) ([]common.Hash, error)
+
+	// GetFilterLogs returns all logs matching the criteria of a log filter.
+	//
+	// https://github.com/ethereum/wiki/wiki/JSON-RPC#eth_getfilterlogs
+	GetFilterLogs(ctx context.Context, id ethrpc.ID) ([]types.Log, error)
+
+	// UninstallFilter removes the filter with the given id.
+	//
+	// https://github.com/ethereum/wiki/wiki/JSON-RPC#eth_uninstallfilter
+	UninstallFilter(ctx context.Context, id ethrpc.ID) (bool, error)
 }
 
 type publicFilter struct {
@@ -805,6 +877,44 @@ func NewPublicFilter(client *ethrpc.Client) PublicFilter {
 	}
 }
 
+// toFilterArg encodes crit as the filter object of eth_newFilter and the logs
+// subscription.
+func toFilterArg(crit ethereum.FilterQuery) (interface{}, error) {
+	arg := map[string]interface{}{
+		"address": crit.Addresses,
+		"topics":  crit.Topics,
+	}
+	if crit.BlockHash != nil {
+		if crit.FromBlock != nil || crit.ToBlock != nil {
+			return nil, errors.New("cannot specify both BlockHash and FromBlock/ToBlock")
+		}
+		arg["blockHash"] = *crit.BlockHash
+		return arg, nil
+	}
+	if crit.FromBlock != nil {
+		arg["fromBlock"] = toBlockNumArg(crit.FromBlock)
+	}
+	if crit.ToBlock != nil {
+		arg["toBlock"] = toBlockNumArg(crit.ToBlock)
+	}
+	return arg, nil
+}
+
+// toBlockNumArg encodes number as a block parameter, mapping the negative
+// ethrpc.BlockNumber values to their tags such as "latest" and "pending".
+func toBlockNumArg(number *big.Int) string {
+	if number == nil {
+		return "latest"
+	}
+	if number.Sign() >= 0 {
+		return hexutil.EncodeBig(number)
+	}
+	if number.IsInt64() {
+		return ethrpc.BlockNumber(number.Int64()).String()
+	}
+	return fmt.Sprintf("<invalid %d>", number)
+}
+
 // NewPendingTransactionFilter creates a filter that fetches pending transaction hashes
 // as transactions enter the pending state.
 //
@@ -823,13 +933,8 @@ func (pub *publicFilter) NewPendingTransactionFilter(ctx context.Context) (ethrp
 
 // NewPendingTransactions creates a subscription that is triggered each time a transaction
 // enters the transaction pool and was signed from one of the transactions this nodes manages.
-func (pub *publicFilter) NewPendingTransactions(ctx context.Context) (*ethrpc.Subscription, error) {
-	var r *ethrpc.Subscription
-	err := pub.client.CallContext(ctx, &r, "eth_newPendingTransactions")
-	if err != nil {
-		return r, err
-	}
-	return r, nil
+func (pub *publicFilter) NewPendingTransactions(ctx context.Context, ch chan<- common.Hash) (*ethrpc.ClientSubscription, error) {
+	return pub.client.EthSubscribe(ctx, ch, "newPendingTransactions")
 }
 
 // NewBlockFilter creates a filter that fetches blocks that are imported into the chain.
@@ -846,21 +951,80 @@ func (pub *publicFilter) NewBlockFilter(ctx context.Context) (ethrpc.ID, error)
 }
 
 // NewHeads send a notification each time a new (header) block is appended to the chain.
-func (pub *publicFilter) NewHeads(ctx context.Context) (*ethrpc.Subscription, error) {
-	var r *ethrpc.Subscription
-	err := pub.client.CallContext(ctx, &r, "eth_newHeads")
+func (pub *publicFilter) NewHeads(ctx context.Context, ch chan<- *types.Header) (*ethrpc.ClientSubscription, error) {
+	return pub.client.EthSubscribe(ctx, ch, "newHeads")
+}
+
+// Logs creates a subscription that fires for all new log that match the given filter criteria.
+func (pub *publicFilter) Logs(ctx context.Context, crit ethereum.FilterQuery, ch chan<- types.Log) (*ethrpc.ClientSubscription, error) {
+	arg, err := toFilterArg(crit)
+	if err != nil {
+		return nil, err
+	}
+	return pub.client.EthSubscribe(ctx, ch, "logs", arg)
+}
+
+// NewFilter creates a new filter for logs matching the given criteria.
+//
+// https://github.com/ethereum/wiki/wiki/JSON-RPC#eth_newfilter
+func (pub *publicFilter) NewFilter(ctx context.Context, crit ethereum.FilterQuery) (ethrpc.ID, error) {
+	var r ethrpc.ID
+	arg, err := toFilterArg(crit)
+	if err != nil {
+		return r, err
+	}
+	err = pub.client.CallContext(ctx, &r, "eth_newFilter", arg)
 	if err != nil {
 		return r, err
 	}
 	return r, nil
 }
 
-// Logs creates a subscription that fires for all new log that match the given filter criteria.
-func (pub *publicFilter) Logs(ctx context.Context, crit filters.FilterCriteria) (*ethrpc.Subscription, error) {
-	var r *ethrpc.Subscription
-	err := pub.client.CallContext(ctx, &r, "eth_logs")
+// GetFilterChanges returns the logs of a log filter since the last poll.
+//
+// https://github.com/ethereum/wiki/wiki/JSON-RPC#eth_getfilterchanges
+func (pub *publicFilter) GetFilterChanges(ctx context.Context, id ethrpc.ID) ([]types.Log, error) {
+	var r []types.Log
+	err := pub.client.CallContext(ctx, &r, "eth_getFilterChanges", id)
 	if err != nil {
-		return r, err
+		return nil, err
+	}
+	return r, nil
+}
+
+// GetFilterHashChanges returns the block or transaction hashes of a block or
+// pending transaction filter since the last poll.
+//
+// https://github.com/ethereum/wiki/wiki/JSON-RPC#eth_getfilterchanges
+func (pub *publicFilter) GetFilterHashChanges(ctx context.Context, id ethrpc.ID) ([]common.Hash, error) {
+	var r []common.Hash
+	err := pub.client.CallContext(ctx, &r, "eth_getFilterChanges", id)
+	if err != nil {
+		return nil, err
+	}
+	return r, nil
+}
+
+// GetFilterLogs returns all logs matching the criteria of a log filter.
+//
+// https://github.com/ethereum/wiki/wiki/JSON-RPC#eth_getfilterlogs
+func (pub *publicFilter) GetFilterLogs(ctx context.Context, id ethrpc.ID) ([]types.Log, error) {
+	var r []types.Log
+	err := pub.client.CallContext(ctx, &r, "eth_getFilterLogs", id)
+	if err != nil {
+		return nil, err
+	}
+	return r, nil
+}
+
+// UninstallFilter removes the filter with the given id.
+//
+// https://github.com/ethereum/wiki/wiki/JSON-RPC#eth_uninstallfilter
+func (pub *publicFilter) UninstallFilter(ctx context.Context, id ethrpc.ID) (bool, error) {
+	var r bool
+	err := pub.client.CallContext(ctx, &r, "eth_uninstallFilter", id)
+	if err != nil {
+		return false, err
 	}
 	return r, nil
 }
@@ -0,0 +1,328 @@
+package rpc
+
+import (
+	"context"
+	"math/big"
+	"testing"
+
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/core/types"
+	ethrpc "github.com/ethereum/go-ethereum/rpc"
+)
+
+const (
+	testAddr = "0x1000000000000000000000000000000000000001"
+	testHash = "0x0000000000000000000000000000000000000000000000000000000000000001"
+)
+
+func TestEthWire(t *testing.T) {
+	client, stub := newRPCStub(t)
+	e := NewEth(client)
+
+	var (
+		addr     = common.HexToAddress(testAddr)
+		hash     = common.HexToHash(testHash)
+		callArgs = CallArgs{
+			From:  addr,
+			To:    addr,
+			Gas:   hexutil.Big(*big.NewInt(21000)),
+			Value: hexutil.Big(*big.NewInt(1)),
+			Data:  hexutil.Bytes{0x01},
+		}
+		callJSON = `{"from":"` + testAddr + `","to":"` + testAddr + `","gas":"0x5208","gasPrice":"0x0","value":"0x1","data":"0x01"}`
+		sendArgs = SendTxArgs{
+			From:  addr,
+			To:    addr,
+			Gas:   hexutil.Big(*big.NewInt(21000)),
+			Nonce: 3,
+		}
+		sendJSON = `{"from":"` + testAddr + `","to":"` + testAddr + `","gas":"0x5208","gasPrice":"0x0","value":"0x0","data":"0x","nonce":"0x3"}`
+		crit     = ethereum.FilterQuery{
+			FromBlock: big.NewInt(1),
+			ToBlock:   big.NewInt(2),
+			Addresses: []common.Address{addr},
+			Topics:    [][]common.Hash{{hash}},
+		}
+		critJSON = `{"address":["` + testAddr + `"],"fromBlock":"0x1","toBlock":"0x2","topics":[["` + testHash + `"]]}`
+	)
+
+	stub.run(t, []wireCase{
+		// PublicTransactionPool
+		{"GetBlockTransactionCountByNumber", func(ctx context.Context) error {
+			_, err := e.GetBlockTransactionCountByNumber(ctx, "latest")
+			return err
+		}, "eth_getBlockTransactionCountByNumber", `["latest"]`, `"0x1"`},
+		{"GetBlockTransactionCountByHash", func(ctx context.Context) error {
+			_, err := e.GetBlockTransactionCountByHash(ctx, hash)
+			return err
+		}, "eth_getBlockTransactionCountByHash", `["` + testHash + `"]`, `"0x1"`},
+		{"GetTransactionByBlockNumberAndIndex", func(ctx context.Context) error {
+			_, err := e.GetTransactionByBlockNumberAndIndex(ctx, "0x10", 2)
+			return err
+		}, "eth_getTransactionByBlockNumberAndIndex", `["0x10","0x2"]`, `null`},
+		{"GetTransactionByBlockHashAndIndex", func(ctx context.Context) error {
+			_, err := e.GetTransactionByBlockHashAndIndex(ctx, hash, 2)
+			return err
+		}, "eth_getTransactionByBlockHashAndIndex", `["` + testHash + `","0x2"]`, `null`},
+		{"GetRawTransactionByBlockNumberAndIndex", func(ctx context.Context) error {
+			_, err := e.GetRawTransactionByBlockNumberAndIndex(ctx, "0x10", 2)
+			return err
+		}, "eth_getRawTransactionByBlockNumberAndIndex", `["0x10","0x2"]`, `"0x01"`},
+		{"GetRawTransactionByBlockHashAndIndex", func(ctx context.Context) error {
+			_, err := e.GetRawTransactionByBlockHashAndIndex(ctx, hash, 2)
+			return err
+		}, "eth_getRawTransactionByBlockHashAndIndex", `["` + testHash + `","0x2"]`, `"0x01"`},
+		{"GetTransactionCount", func(ctx context.Context) error {
+			_, err := e.GetTransactionCount(ctx, addr, "pending")
+			return err
+		}, "eth_getTransactionCount", `["` + testAddr + `","pending"]`, `"0x1"`},
+		{"GetTransactionByHash", func(ctx context.Context) error {
+			_, err := e.GetTransactionByHash(ctx, hash)
+			return err
+		}, "eth_getTransactionByHash", `["` + testHash + `"]`, `null`},
+		{"GetRawTransactionByHash", func(ctx context.Context) error {
+			_, err := e.GetRawTransactionByHash(ctx, hash)
+			return err
+		}, "eth_getRawTransactionByHash", `["` + testHash + `"]`, `"0x01"`},
+		{"GetTransactionReceipt", func(ctx context.Context) error {
+			_, err := e.GetTransactionReceipt(ctx, hash)
+			return err
+		}, "eth_getTransactionReceipt", `["` + testHash + `"]`, `null`},
+		{"SendTransaction", func(ctx context.Context) error {
+			_, err := e.SendTransaction(ctx, sendArgs)
+			return err
+		}, "eth_sendTransaction", `[` + sendJSON + `]`, `"` + testHash + `"`},
+		{"SendRawTransaction", func(ctx context.Context) error {
+			_, err := e.SendRawTransaction(ctx, hexutil.Bytes{0xf8, 0x01})
+			return err
+		}, "eth_sendRawTransaction", `["0xf801"]`, `"` + testHash + `"`},
+		{"Sign", func(ctx context.Context) error {
+			_, err := e.Sign(ctx, addr, hexutil.Bytes{0x01})
+			return err
+		}, "eth_sign", `["` + testAddr + `","0x01"]`, `"0x01"`},
+		{"SignTransaction", func(ctx context.Context) error {
+			_, err := e.SignTransaction(ctx, sendArgs)
+			return err
+		}, "eth_signTransaction", `[` + sendJSON + `]`, `null`},
+		{"PendingTransactions", func(ctx context.Context) error {
+			_, err := e.PendingTransactions(ctx)
+			return err
+		}, "eth_pendingTransactions", `[]`, `[]`},
+		{"Resend", func(ctx context.Context) error {
+			_, err := e.Resend(ctx, sendArgs, hexutil.Big(*big.NewInt(2)), hexutil.Big(*big.NewInt(21000)))
+			return err
+		}, "eth_resend", `[` + sendJSON + `,"0x2","0x5208"]`, `"` + testHash + `"`},
+
+		// PublicEthereum
+		{"GasPrice", func(ctx context.Context) error {
+			_, err := e.GasPrice(ctx)
+			return err
+		}, "eth_gasPrice", `[]`, `"0x3b9aca00"`},
+		{"ProtocolVersion", func(ctx context.Context) error {
+			_, err := e.ProtocolVersion(ctx)
+			return err
+		}, "eth_protocolVersion", `[]`, `"0x41"`},
+		{"Syncing", func(ctx context.Context) error {
+			_, err := e.Syncing(ctx)
+			return err
+		}, "eth_syncing", `[]`, `false`},
+		{"Etherbase", func(ctx context.Context) error {
+			_, err := e.Etherbase(ctx)
+			return err
+		}, "eth_etherbase", `[]`, `"` + testAddr + `"`},
+		{"Coinbase", func(ctx context.Context) error {
+			_, err := e.Coinbase(ctx)
+			return err
+		}, "eth_coinbase", `[]`, `"` + testAddr + `"`},
+		{"Hashrate", func(ctx context.Context) error {
+			_, err := e.Hashrate(ctx)
+			return err
+		}, "eth_hashrate", `[]`, `"0x0"`},
+
+		// PublicBlockChain
+		{"BlockNumber", func(ctx context.Context) error {
+			_, err := e.BlockNumber(ctx)
+			return err
+		}, "eth_blockNumber", `[]`, `"0x10"`},
+		{"GetBalance", func(ctx context.Context) error {
+			_, err := e.GetBalance(ctx, addr, "latest")
+			return err
+		}, "eth_getBalance", `["` + testAddr + `","latest"]`, `"0x1"`},
+		{"GetBlockByNumber", func(ctx context.Context) error {
+			_, err := e.GetBlockByNumber(ctx, "0x10", true)
+			return err
+		}, "eth_getBlockByNumber", `["0x10",true]`, `null`},
+		{"GetBlockByHash", func(ctx context.Context) error {
+			_, err := e.GetBlockByHash(ctx, hash, false)
+			return err
+		}, "eth_getBlockByHash", `["` + testHash + `",false]`, `null`},
+		{"GetUncleByBlockNumberAndIndex", func(ctx context.Context) error {
+			_, err := e.GetUncleByBlockNumberAndIndex(ctx, "0x10", 1)
+			return err
+		}, "eth_getUncleByBlockNumberAndIndex", `["0x10","0x1"]`, `null`},
+		{"GetUncleByBlockHashAndIndex", func(ctx context.Context) error {
+			_, err := e.GetUncleByBlockHashAndIndex(ctx, hash, 1)
+			return err
+		}, "eth_getUncleByBlockHashAndIndex", `["` + testHash + `","0x1"]`, `null`},
+		{"GetUncleCountByBlockNumber", func(ctx context.Context) error {
+			_, err := e.GetUncleCountByBlockNumber(ctx, "0x10")
+			return err
+		}, "eth_getUncleCountByBlockNumber", `["0x10"]`, `"0x0"`},
+		{"GetUncleCountByBlockHash", func(ctx context.Context) error {
+			_, err := e.GetUncleCountByBlockHash(ctx, hash)
+			return err
+		}, "eth_getUncleCountByBlockHash", `["` + testHash + `"]`, `"0x0"`},
+		{"GetCode", func(ctx context.Context) error {
+			_, err := e.GetCode(ctx, addr, "latest")
+			return err
+		}, "eth_getCode", `["` + testAddr + `","latest"]`, `"0x"`},
+		{"GetStorageAt", func(ctx context.Context) error {
+			_, err := e.GetStorageAt(ctx, addr, "0x0", "latest")
+			return err
+		}, "eth_getStorageAt", `["` + testAddr + `","0x0","latest"]`, `"0x00"`},
+		{"Call", func(ctx context.Context) error {
+			_, err := e.Call(ctx, callArgs, "latest")
+			return err
+		}, "eth_call", `[` + callJSON + `,"latest"]`, `"0x"`},
+		{"EstimateGas", func(ctx context.Context) error {
+			_, err := e.EstimateGas(ctx, callArgs)
+			return err
+		}, "eth_estimateGas", `[` + callJSON + `]`, `"0x5208"`},
+
+		// PublicAccount
+		{"Accounts", func(ctx context.Context) error {
+			_, err := e.Accounts(ctx)
+			return err
+		}, "eth_accounts", `[]`, `["` + testAddr + `"]`},
+
+		// PublicMiner
+		{"Mining", func(ctx context.Context) error {
+			_, err := e.Mining(ctx)
+			return err
+		}, "eth_mining", `[]`, `false`},
+		{"SubmitWork", func(ctx context.Context) error {
+			_, err := e.SubmitWork(ctx, types.EncodeNonce(1), hash, hash)
+			return err
+		}, "eth_submitWork", `["0x0000000000000001","` + testHash + `","` + testHash + `"]`, `true`},
+		{"GetWork", func(ctx context.Context) error {
+			_, err := e.GetWork(ctx)
+			return err
+		}, "eth_getWork", `[]`, `["` + testHash + `","` + testHash + `","` + testHash + `"]`},
+		{"SubmitHashrate", func(ctx context.Context) error {
+			_, err := e.SubmitHashrate(ctx, 100, hash)
+			return err
+		}, "eth_submitHashrate", `["0x64","` + testHash + `"]`, `true`},
+
+		// PublicDownloader
+		{"SubscribeSyncStatus", func(ctx context.Context) error {
+			sub, err := e.SubscribeSyncStatus(ctx, make(chan *SyncStatus))
+			if err == nil {
+				sub.Unsubscribe()
+			}
+			return err
+		}, "eth_subscribe", `["syncing"]`, `"0x1"`},
+
+		// PublicFilter
+		{"NewPendingTransactionFilter", func(ctx context.Context) error {
+			_, err := e.NewPendingTransactionFilter(ctx)
+			return err
+		}, "eth_newPendingTransactionFilter", `[]`, `"0x1"`},
+		{"NewPendingTransactions", func(ctx context.Context) error {
+			sub, err := e.NewPendingTransactions(ctx, make(chan common.Hash))
+			if err == nil {
+				sub.Unsubscribe()
+			}
+			return err
+		}, "eth_subscribe", `["newPendingTransactions"]`, `"0x1"`},
+		{"NewBlockFilter", func(ctx context.Context) error {
+			_, err := e.NewBlockFilter(ctx)
+			return err
+		}, "eth_newBlockFilter", `[]`, `"0x1"`},
+		{"NewHeads", func(ctx context.Context) error {
+			sub, err := e.NewHeads(ctx, make(chan *types.Header))
+			if err == nil {
+				sub.Unsubscribe()
+			}
+			return err
+		}, "eth_subscribe", `["newHeads"]`, `"0x1"`},
+		{"Logs", func(ctx context.Context) error {
+			sub, err := e.Logs(ctx, crit, make(chan types.Log))
+			if err == nil {
+				sub.Unsubscribe()
+			}
+			return err
+		}, "eth_subscribe", `["logs",` + critJSON + `]`, `"0x1"`},
+		{"NewFilter", func(ctx context.Context) error {
+			_, err := e.NewFilter(ctx, crit)
+			return err
+		}, "eth_newFilter", `[` + critJSON + `]`, `"0x1"`},
+		{"GetFilterChanges", func(ctx context.Context) error {
+			_, err := e.GetFilterChanges(ctx, "0x1")
+			return err
+		}, "eth_getFilterChanges", `["0x1"]`, `[]`},
+		{"GetFilterHashChanges", func(ctx context.Context) error {
+			_, err := e.GetFilterHashChanges(ctx, "0x1")
+			return err
+		}, "eth_getFilterChanges", `["0x1"]`, `["` + testHash + `"]`},
+		{"GetFilterLogs", func(ctx context.Context) error {
+			_, err := e.GetFilterLogs(ctx, "0x1")
+			return err
+		}, "eth_getFilterLogs", `["0x1"]`, `[]`},
+		{"UninstallFilter", func(ctx context.Context) error {
+			_, err := e.UninstallFilter(ctx, "0x1")
+			return err
+		}, "eth_uninstallFilter", `["0x1"]`, `true`},
+	})
+}
+
+func TestSyncStatusUnmarshal(t *testing.T) {
+	tests := []struct {
+		input string
+		want  SyncStatus
+	}{
+		{`false`, SyncStatus{}},
+		{`{"syncing":false}`, SyncStatus{}},
+		{
+			`{"syncing":true,"status":{"startingBlock":"0x1","currentBlock":"0x10","highestBlock":"0x20","pulledStates":5,"knownStates":9}}`,
+			SyncStatus{Syncing: true, Progress: SyncProgress{StartingBlock: 1, CurrentBlock: 16, HighestBlock: 32, PulledStates: 5, KnownStates: 9}},
+		},
+	}
+	for _, test := range tests {
+		var have SyncStatus
+		if err := have.UnmarshalJSON([]byte(test.input)); err != nil {
+			t.Fatalf("%s: %v", test.input, err)
+		}
+		if have != test.want {
+			t.Errorf("%s: have %+v, want %+v", test.input, have, test.want)
+		}
+	}
+}
+
+func TestToFilterArg(t *testing.T) {
+	arg, err := toFilterArg(ethereum.FilterQuery{
+		FromBlock: big.NewInt(int64(ethrpc.FinalizedBlockNumber)),
+		ToBlock:   big.NewInt(int64(ethrpc.PendingBlockNumber)),
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	m := arg.(map[string]interface{})
+	if m["fromBlock"] != "finalized" || m["toBlock"] != "pending" {
+		t.Errorf("block tags mismatch: have %v, %v", m["fromBlock"], m["toBlock"])
+	}
+
+	arg, err = toFilterArg(ethereum.FilterQuery{ToBlock: big.NewInt(int64(ethrpc.LatestBlockNumber))})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if m := arg.(map[string]interface{}); m["toBlock"] != "latest" {
+		t.Errorf("latest tag mismatch: have %v", m["toBlock"])
+	}
+
+	hash := common.HexToHash(testHash)
+	if _, err := toFilterArg(ethereum.FilterQuery{BlockHash: &hash, FromBlock: big.NewInt(1)}); err == nil {
+		t.Error("expected error for a block hash with a block range")
+	}
+}
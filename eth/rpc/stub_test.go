@@ -0,0 +1,118 @@
+package rpc
+
+import (
+	"context"
+	"encoding/json"
+	"net"
+	"reflect"
+	"sync"
+	"testing"
+	"time"
+
+	ethrpc "github.com/ethereum/go-ethereum/rpc"
+)
+
+// wireCase is a binding call along with the request it must put on the wire
+// and the canned result the stub answers it with.
+type wireCase struct {
+	name   string
+	call   func(ctx context.Context) error
+	method string
+	params string
+	result string
+}
+
+type stubRequest struct {
+	Method string
+	Params json.RawMessage
+}
+
+// rpcStub is an in-process JSON-RPC server recording the requests it gets.
+type rpcStub struct {
+	mu       sync.Mutex
+	result   json.RawMessage
+	requests chan stubRequest
+}
+
+func newRPCStub(t *testing.T) (*ethrpc.Client, *rpcStub) {
+	clientConn, serverConn := net.Pipe()
+	stub := &rpcStub{requests: make(chan stubRequest, 16)}
+	go stub.serve(serverConn)
+
+	client, err := ethrpc.DialIO(context.Background(), clientConn, clientConn)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() {
+		client.Close()
+		serverConn.Close()
+	})
+	return client, stub
+}
+
+func (s *rpcStub) serve(conn net.Conn) {
+	dec, enc := json.NewDecoder(conn), json.NewEncoder(conn)
+	for {
+		var req struct {
+			ID     json.RawMessage `json:"id"`
+			Method string          `json:"method"`
+			Params json.RawMessage `json:"params"`
+		}
+		if err := dec.Decode(&req); err != nil {
+			return
+		}
+		result := json.RawMessage("true")
+		if req.Method != "eth_unsubscribe" {
+			s.requests <- stubRequest{Method: req.Method, Params: req.Params}
+			s.mu.Lock()
+			result = s.result
+			s.mu.Unlock()
+		}
+		resp := map[string]interface{}{"jsonrpc": "2.0", "id": req.ID, "result": result}
+		if err := enc.Encode(resp); err != nil {
+			return
+		}
+	}
+}
+
+// run executes every case against the stub, checking the method name and the
+// parameters of the request each one sends.
+func (s *rpcStub) run(t *testing.T, cases []wireCase) {
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			s.mu.Lock()
+			s.result = json.RawMessage(c.result)
+			s.mu.Unlock()
+
+			ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+			defer cancel()
+			if err := c.call(ctx); err != nil {
+				t.Fatalf("call failed: %v", err)
+			}
+			var req stubRequest
+			select {
+			case req = <-s.requests:
+			case <-ctx.Done():
+				t.Fatal("no request received")
+			}
+			if req.Method != c.method {
+				t.Errorf("method mismatch: have %s, want %s", req.Method, c.method)
+			}
+			var have, want interface{}
+			if len(req.Params) > 0 {
+				if err := json.Unmarshal(req.Params, &have); err != nil {
+					t.Fatalf("invalid params %s: %v", req.Params, err)
+				}
+			}
+			if err := json.Unmarshal([]byte(c.params), &want); err != nil {
+				t.Fatalf("invalid expected params %s: %v", c.params, err)
+			}
+			if have == nil {
+				have = []interface{}{}
+			}
+			if !reflect.DeepEqual(have, want) {
+				t.Errorf("params mismatch: have %s, want %s", req.Params, c.params)
+			}
+		})
+	}
+}
@@ -0,0 +1,80 @@
+package rpc
+
+import (
+	"context"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	client "github.com/ethereum/go-ethereum/rpc"
+)
+
+// TxPoolStatus is the number of pending and queued transactions in the pool.
+type TxPoolStatus struct {
+	Pending hexutil.Uint `json:"pending"`
+	Queued  hexutil.Uint `json:"queued"`
+}
+
+// TxPoolContent holds the pool transactions by sender and nonce.
+type TxPoolContent struct {
+	Pending map[common.Address]map[uint64]*RPCTransaction `json:"pending"`
+	Queued  map[common.Address]map[uint64]*RPCTransaction `json:"queued"`
+}
+
+// TxPoolInspection holds one-line summaries of the pool transactions by sender
+// and nonce.
+type TxPoolInspection struct {
+	Pending map[common.Address]map[uint64]string `json:"pending"`
+	Queued  map[common.Address]map[uint64]string `json:"queued"`
+}
+
+//go:generate mockgen -source=txpool.go -destination=mock_txpool.go -package=rpc
+type TxPool interface {
+	// Status returns the number of pending and queued transactions in the pool.
+	Status(ctx context.Context) (*TxPoolStatus, error)
+	// Content returns the transactions contained within the transaction pool.
+	Content(ctx context.Context) (*TxPoolContent, error)
+	// Inspect retrieves the content of the transaction pool and flattens it into an
+	// easily inspectable list.
+	Inspect(ctx context.Context) (*TxPoolInspection, error)
+}
+
+type txPool struct {
+	client *client.Client
+}
+
+func NewTxPool(client *client.Client) TxPool {
+	return &txPool{
+		client: client,
+	}
+}
+
+// Status returns the number of pending and queued transactions in the pool.
+func (pub *txPool) Status(ctx context.Context) (*TxPoolStatus, error) {
+	var r *TxPoolStatus
+	err := pub.client.CallContext(ctx, &r, "txpool_status")
+	if err != nil {
+		return nil, err
+	}
+	return r, nil
+}
+
+// Content returns the transactions contained within the transaction pool.
+func (pub *txPool) Content(ctx context.Context) (*TxPoolContent, error) {
+	var r *TxPoolContent
+	err := pub.client.CallContext(ctx, &r, "txpool_content")
+	if err != nil {
+		return nil, err
+	}
+	return r, nil
+}
+
+// Inspect retrieves the content of the transaction pool and flattens it into an
+// easily inspectable list.
+func (pub *txPool) Inspect(ctx context.Context) (*TxPoolInspection, error) {
+	var r *TxPoolInspection
+	err := pub.client.CallContext(ctx, &r, "txpool_inspect")
+	if err != nil {
+		return nil, err
+	}
+	return r, nil
+}
@@ -0,0 +1,37 @@
+package rpc
+
+import (
+	"context"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+)
+
+func TestTxPoolWire(t *testing.T) {
+	client, stub := newRPCStub(t)
+	pool := NewTxPool(client)
+
+	var content *TxPoolContent
+	stub.run(t, []wireCase{
+		{"Status", func(ctx context.Context) error {
+			_, err := pool.Status(ctx)
+			return err
+		}, "txpool_status", `[]`, `{"pending":"0xa","queued":"0x7"}`},
+		{"Content", func(ctx context.Context) (err error) {
+			content, err = pool.Content(ctx)
+			return err
+		}, "txpool_content", `[]`, `{"pending":{"` + testAddr + `":{"3":{"hash":"` + testHash + `","nonce":"0x3","gasPrice":"0x3b9aca00"}}},"queued":{"` + testAddr + `":{"5":{"hash":"` + testHash + `","nonce":"0x5","gasPrice":"0x3b9aca00"}}}}`},
+		{"Inspect", func(ctx context.Context) error {
+			_, err := pool.Inspect(ctx)
+			return err
+		}, "txpool_inspect", `[]`, `{"pending":{"` + testAddr + `":{"3":"0x1000000000000000000000000000000000000001: 0 wei + 21000 gas × 1000000000 wei"}},"queued":{}}`},
+	})
+
+	addr := common.HexToAddress(testAddr)
+	if tx := content.Pending[addr][3]; tx == nil || tx.GasPrice.ToInt().Int64() != 1e9 {
+		t.Fatalf("unexpected pending content: %+v", content.Pending)
+	}
+	if tx := content.Queued[addr][5]; tx == nil || uint64(tx.Nonce) != 5 {
+		t.Fatalf("unexpected queued content: %+v", content.Queued)
+	}
+}
@@ -1,83 +1,289 @@
 package eth
 
 import (
+	"errors"
+	"math/big"
+
 	"github.com/ethereum/go-ethereum/common"
 	"github.com/ethereum/go-ethereum/common/hexutil"
-	"math/big"
+	"github.com/ethereum/go-ethereum/core/types"
 )
 
+var (
+	// ErrTxPending is returned for block related fields of a pending transaction.
+	ErrTxPending = errors.New("transaction is pending")
+	// ErrTxFieldMissing is returned when the node omitted a required field.
+	ErrTxFieldMissing = errors.New("transaction field missing")
+	// ErrTxHashMismatch is returned when the fields reported by the node do
+	// not hash to the reported transaction hash.
+	ErrTxHashMismatch = errors.New("transaction hash mismatch")
+)
+
+// RpcEthTransaction is a transaction as returned by the eth_getTransactionBy*
+// JSON-RPC methods.
 type RpcEthTransaction struct {
 	Txdata
 	txExtraInfo
 }
 
 type Txdata struct {
-	AccountNonce string         `json:"nonce"    gencodec:"required"`
-	Price        string         `json:"gasPrice"   gencodec:"required"`
-	GasLimit     string         `json:"gas"      gencodec:"required"`
-	Recipient    common.Address `json:"to"       rlp:"nil"` // nil means contract creation
-	Amount       string         `json:"value"    gencodec:"required"`
-	Payload      []byte         `json:"input"    gencodec:"required"`
+	Type         hexutil.Uint64    `json:"type"`
+	ChainID      *hexutil.Big      `json:"chainId,omitempty"`
+	AccountNonce hexutil.Uint64    `json:"nonce"    gencodec:"required"`
+	Price        *hexutil.Big      `json:"gasPrice"   gencodec:"required"`
+	GasTipCap    *hexutil.Big      `json:"maxPriorityFeePerGas,omitempty"`
+	GasFeeCap    *hexutil.Big      `json:"maxFeePerGas,omitempty"`
+	GasLimit     hexutil.Uint64    `json:"gas"      gencodec:"required"`
+	Recipient    *common.Address   `json:"to"       rlp:"nil"` // nil means contract creation
+	Amount       *hexutil.Big      `json:"value"    gencodec:"required"`
+	Payload      hexutil.Bytes     `json:"input"    gencodec:"required"`
+	AccessList   *types.AccessList `json:"accessList,omitempty"`
 
 	// Signature values
-	V string `json:"v" gencodec:"required"`
-	R string `json:"r" gencodec:"required"`
-	S string `json:"s" gencodec:"required"`
+	V *hexutil.Big `json:"v" gencodec:"required"`
+	R *hexutil.Big `json:"r" gencodec:"required"`
+	S *hexutil.Big `json:"s" gencodec:"required"`
 
 	// This is only used when marshaling to JSON.
 	Hash common.Hash `json:"hash" rlp:"-"`
 }
 
 type txExtraInfo struct {
-	BlockNumber string         `json:"blockNumber,omitempty"`
-	BlockHash   common.Hash    `json:"blockHash,omitempty"`
-	From        common.Address `json:"from,omitempty"`
+	BlockNumber      *hexutil.Big    `json:"blockNumber,omitempty"`
+	BlockHash        *common.Hash    `json:"blockHash,omitempty"`
+	TransactionIndex *hexutil.Uint64 `json:"transactionIndex,omitempty"`
+	From             common.Address  `json:"from,omitempty"`
+}
+
+// NewRpcEthTransaction converts a signed transaction into its RPC form. The
+// sender is recovered with signer; block fields are left empty.
+func NewRpcEthTransaction(tx *types.Transaction, signer types.Signer) (*RpcEthTransaction, error) {
+	from, err := types.Sender(signer, tx)
+	if err != nil {
+		return nil, err
+	}
+	v, r, s := tx.RawSignatureValues()
+	rt := &RpcEthTransaction{
+		Txdata: Txdata{
+			Type:         hexutil.Uint64(tx.Type()),
+			AccountNonce: hexutil.Uint64(tx.Nonce()),
+			Price:        (*hexutil.Big)(tx.GasPrice()),
+			GasLimit:     hexutil.Uint64(tx.Gas()),
+			Recipient:    tx.To(),
+			Amount:       (*hexutil.Big)(tx.Value()),
+			Payload:      tx.Data(),
+			V:            (*hexutil.Big)(v),
+			R:            (*hexutil.Big)(r),
+			S:            (*hexutil.Big)(s),
+			Hash:         tx.Hash(),
+		},
+		txExtraInfo: txExtraInfo{
+			From: from,
+		},
+	}
+	if tx.Type() != types.LegacyTxType {
+		al := tx.AccessList()
+		rt.ChainID = (*hexutil.Big)(tx.ChainId())
+		rt.AccessList = &al
+	}
+	if tx.Type() == types.DynamicFeeTxType {
+		rt.GasTipCap = (*hexutil.Big)(tx.GasTipCap())
+		rt.GasFeeCap = (*hexutil.Big)(tx.GasFeeCap())
+	}
+	return rt, nil
+}
+
+// Transaction rebuilds the signed transaction from its RPC form. The result
+// hashes to Hash and its sender is recovered, so later types.Sender calls are
+// served from cache.
+func (r *RpcEthTransaction) Transaction() (*types.Transaction, error) {
+	if r.V == nil || r.R == nil || r.S == nil || r.Amount == nil {
+		return nil, ErrTxFieldMissing
+	}
+	var (
+		v, rr, s = (*big.Int)(r.V), (*big.Int)(r.R), (*big.Int)(r.S)
+		al       types.AccessList
+		inner    types.TxData
+	)
+	if r.AccessList != nil {
+		al = *r.AccessList
+	}
+	switch r.Type {
+	case types.LegacyTxType:
+		if r.Price == nil {
+			return nil, ErrTxFieldMissing
+		}
+		inner = &types.LegacyTx{
+			Nonce:    uint64(r.AccountNonce),
+			GasPrice: (*big.Int)(r.Price),
+			Gas:      uint64(r.GasLimit),
+			To:       r.Recipient,
+			Value:    (*big.Int)(r.Amount),
+			Data:     r.Payload,
+			V:        v,
+			R:        rr,
+			S:        s,
+		}
+	case types.AccessListTxType:
+		if r.Price == nil || r.ChainID == nil {
+			return nil, ErrTxFieldMissing
+		}
+		inner = &types.AccessListTx{
+			ChainID:    (*big.Int)(r.ChainID),
+			Nonce:      uint64(r.AccountNonce),
+			GasPrice:   (*big.Int)(r.Price),
+			Gas:        uint64(r.GasLimit),
+			To:         r.Recipient,
+			Value:      (*big.Int)(r.Amount),
+			Data:       r.Payload,
+			AccessList: al,
+			V:          v,
+			R:          rr,
+			S:          s,
+		}
+	case types.DynamicFeeTxType:
+		if r.GasTipCap == nil || r.GasFeeCap == nil || r.ChainID == nil {
+			return nil, ErrTxFieldMissing
+		}
+		inner = &types.DynamicFeeTx{
+			ChainID:    (*big.Int)(r.ChainID),
+			Nonce:      uint64(r.AccountNonce),
+			GasTipCap:  (*big.Int)(r.GasTipCap),
+			GasFeeCap:  (*big.Int)(r.GasFeeCap),
+			Gas:        uint64(r.GasLimit),
+			To:         r.Recipient,
+			Value:      (*big.Int)(r.Amount),
+			Data:       r.Payload,
+			AccessList: al,
+			V:          v,
+			R:          rr,
+			S:          s,
+		}
+	default:
+		return nil, types.ErrTxTypeNotSupported
+	}
+
+	tx := types.NewTx(inner)
+	if tx.Hash() != r.Hash {
+		return nil, ErrTxHashMismatch
+	}
+	if _, err := types.Sender(types.LatestSignerForChainID(tx.ChainId()), tx); err != nil {
+		return nil, err
+	}
+	return tx, nil
 }
 
 func (r *RpcEthTransaction) FromAddress() common.Address {
 	return r.From
 }
 
+// ToAddress returns the recipient, or the zero address for contract creations.
 func (r *RpcEthTransaction) ToAddress() common.Address {
-	return r.Recipient
+	if r.Recipient == nil {
+		return common.Address{}
+	}
+	return *r.Recipient
 }
 
-func (r *RpcEthTransaction) GetETHAmount() *big.Float {
-	a, err := hexutil.DecodeBig(r.Amount)
-	if err != nil {
-		log.Info("error to decode int - ETH value")
+// IsContractCreation reports whether the transaction deploys a contract.
+func (r *RpcEthTransaction) IsContractCreation() bool {
+	return r.Recipient == nil
+}
+
+// GetETHAmount returns the value transferred, in ether.
+func (r *RpcEthTransaction) GetETHAmount() (Amount, error) {
+	if r.Amount == nil {
+		return Amount{}, ErrTxFieldMissing
 	}
-	return bigIntFloat(a, 18)
+	return Wei(r.Amount.ToInt()), nil
 }
 
-func (r *RpcEthTransaction) GetETHPrice() *big.Int {
-	a, err := hexutil.DecodeBig(r.Price)
-	if err != nil {
-		log.Info("error to decode int - ETH Price")
+// GetETHPrice returns the gas price: the effective price once mined, the fee
+// cap while a dynamic-fee transaction is pending.
+func (r *RpcEthTransaction) GetETHPrice() (*big.Int, error) {
+	if r.Price == nil {
+		if r.GasFeeCap != nil {
+			return new(big.Int).Set(r.GasFeeCap.ToInt()), nil
+		}
+		return nil, ErrTxFieldMissing
 	}
-	return a
+	return new(big.Int).Set(r.Price.ToInt()), nil
 }
 
-func (r *RpcEthTransaction) GetETHGasLimit() *big.Int {
-	a, err := hexutil.DecodeBig(r.GasLimit)
-	if err != nil {
-		log.Info("error to decode int - ETH Gas Limit")
+// GetGasTipCap returns the priority fee cap of a dynamic-fee transaction, or
+// the gas price of older transaction types.
+func (r *RpcEthTransaction) GetGasTipCap() (*big.Int, error) {
+	if r.GasTipCap == nil {
+		return r.GetETHPrice()
 	}
-	return a
+	return new(big.Int).Set(r.GasTipCap.ToInt()), nil
 }
 
-func (r *RpcEthTransaction) GetBlockNumber() *big.Int {
-	a, err := hexutil.DecodeBig(r.BlockNumber)
-	if err != nil {
-		log.Info("error to decode int - ETH Gas Limit")
+// GetGasFeeCap returns the fee cap of a dynamic-fee transaction, or the gas
+// price of older transaction types.
+func (r *RpcEthTransaction) GetGasFeeCap() (*big.Int, error) {
+	if r.GasFeeCap == nil {
+		return r.GetETHPrice()
 	}
-	return a
+	return new(big.Int).Set(r.GasFeeCap.ToInt()), nil
 }
 
-func (r *RpcEthTransaction) GetNounce() *big.Int {
-	a, err := hexutil.DecodeBig(r.AccountNonce)
-	if err != nil {
-		log.Info("error to decode int - ETH Gas Limit")
+// GetETHGasLimit returns the gas limit of the transaction.
+func (r *RpcEthTransaction) GetETHGasLimit() (*big.Int, error) {
+	return new(big.Int).SetUint64(uint64(r.GasLimit)), nil
+}
+
+// GetBlockNumber returns the number of the including block, or ErrTxPending.
+func (r *RpcEthTransaction) GetBlockNumber() (*big.Int, error) {
+	if r.BlockNumber == nil {
+		return nil, ErrTxPending
+	}
+	return new(big.Int).Set(r.BlockNumber.ToInt()), nil
+}
+
+// GetBlockHash returns the hash of the including block, or ErrTxPending.
+func (r *RpcEthTransaction) GetBlockHash() (common.Hash, error) {
+	if r.BlockHash == nil {
+		return common.Hash{}, ErrTxPending
 	}
-	return a
+	return *r.BlockHash, nil
+}
+
+// GetTransactionIndex returns the position in the including block, or
+// ErrTxPending.
+func (r *RpcEthTransaction) GetTransactionIndex() (uint64, error) {
+	if r.TransactionIndex == nil {
+		return 0, ErrTxPending
+	}
+	return uint64(*r.TransactionIndex), nil
+}
+
+// GetChainID returns the chain the transaction is bound to: the explicit
+// chain ID of typed transactions, or the one derived from V for EIP-155
+// transactions. Unprotected transactions report ErrTxFieldMissing.
+func (r *RpcEthTransaction) GetChainID() (*big.Int, error) {
+	if r.ChainID != nil {
+		return new(big.Int).Set(r.ChainID.ToInt()), nil
+	}
+	if r.Type == types.LegacyTxType && r.V != nil {
+		v := r.V.ToInt()
+		if v.BitLen() <= 8 && (v.Uint64() == 27 || v.Uint64() == 28) {
+			return nil, ErrTxFieldMissing
+		}
+		id := new(big.Int).Sub(v, big.NewInt(35))
+		return id.Rsh(id, 1), nil
+	}
+	return nil, ErrTxFieldMissing
+}
+
+// GetNonce returns the nonce of the transaction in the sender's account.
+func (r *RpcEthTransaction) GetNonce() (uint64, error) {
+	return uint64(r.AccountNonce), nil
+}
+
+// GetNounce returns the nonce as a big integer.
+//
+// Deprecated: use GetNonce.
+func (r *RpcEthTransaction) GetNounce() (*big.Int, error) {
+	return new(big.Int).SetUint64(uint64(r.AccountNonce)), nil
 }
@@ -0,0 +1,99 @@
+package eth
+
+import (
+	"encoding/json"
+	"math/big"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/crypto"
+)
+
+func TestRpcEthTransactionRoundTrip(t *testing.T) {
+	key, _ := crypto.GenerateKey()
+	from := crypto.PubkeyToAddress(key.PublicKey)
+	to := common.HexToAddress("0x86fa049857e0209aa7d9e616f7eb3b3b78ecfdb0")
+	chainID := big.NewInt(1337)
+
+	tests := map[string]types.TxData{
+		"legacy": &types.LegacyTx{
+			Nonce:    7,
+			GasPrice: big.NewInt(20000000000),
+			Gas:      21000,
+			To:       &to,
+			Value:    big.NewInt(1e18),
+		},
+		"dynamic fee": &types.DynamicFeeTx{
+			ChainID:   chainID,
+			Nonce:     8,
+			GasTipCap: big.NewInt(1000000000),
+			GasFeeCap: big.NewInt(30000000000),
+			Gas:       60000,
+			To:        &to,
+			Value:     big.NewInt(0),
+			Data:      []byte{0xa9, 0x05, 0x9c, 0xbb},
+			AccessList: types.AccessList{{
+				Address:     to,
+				StorageKeys: []common.Hash{{0x01}},
+			}},
+		},
+	}
+	for name, inner := range tests {
+		t.Run(name, func(t *testing.T) {
+			signer := types.LatestSignerForChainID(chainID)
+			tx, err := types.SignNewTx(key, signer, inner)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			// Decode the payload as a node returns it.
+			data, err := tx.MarshalJSON()
+			if err != nil {
+				t.Fatal(err)
+			}
+			var rt RpcEthTransaction
+			if err := json.Unmarshal(data, &rt); err != nil {
+				t.Fatal(err)
+			}
+			decoded, err := rt.Transaction()
+			if err != nil {
+				t.Fatal(err)
+			}
+			if decoded.Hash() != tx.Hash() {
+				t.Fatalf("hash mismatch: have %x, want %x", decoded.Hash(), tx.Hash())
+			}
+			if sender, err := types.Sender(signer, decoded); err != nil || sender != from {
+				t.Fatalf("sender mismatch: have %x (%v), want %x", sender, err, from)
+			}
+
+			// Converting back to the RPC form and through JSON again is lossless.
+			converted, err := NewRpcEthTransaction(decoded, signer)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if converted.From != from {
+				t.Fatalf("from mismatch: have %x, want %x", converted.From, from)
+			}
+			if data, err = json.Marshal(converted); err != nil {
+				t.Fatal(err)
+			}
+			var again RpcEthTransaction
+			if err := json.Unmarshal(data, &again); err != nil {
+				t.Fatal(err)
+			}
+			if again.From != from {
+				t.Fatalf("from lost through JSON: have %x", again.From)
+			}
+			if decoded, err = again.Transaction(); err != nil || decoded.Hash() != tx.Hash() {
+				t.Fatalf("second round trip mismatch: have %v (%v), want %x", decoded, err, tx.Hash())
+			}
+
+			// Any change to the signed fields breaks the hash.
+			again.AccountNonce++
+			if _, err := again.Transaction(); err != ErrTxHashMismatch {
+				t.Fatalf("tampered nonce: have %v, want %v", err, ErrTxHashMismatch)
+			}
+		})
+	}
+}
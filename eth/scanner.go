@@ -0,0 +1,453 @@
+package eth
+
+import (
+	"context"
+	"errors"
+	"math/big"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+)
+
+const (
+	// maxTopicAddresses bounds the number of watched addresses in the topics
+	// of a single eth_getLogs filter; providers reject oversized filters.
+	maxTopicAddresses = 500
+	// scannerWindow is how many scanned blocks a Scanner remembers to find
+	// the common ancestor after a reorg.
+	scannerWindow = 128
+)
+
+// errScannerReorg reports that the last scanned block is no longer the parent
+// of the next one.
+var errScannerReorg = errors.New("scanned block reorganised away")
+
+// DepositKind tells native ether deposits from ERC-20 ones.
+type DepositKind int
+
+const (
+	// DepositETH is a native ether transfer to a watched address.
+	DepositETH DepositKind = iota
+	// DepositToken is an ERC-20 Transfer event to a watched address.
+	DepositToken
+)
+
+// Deposit is a transfer to a watched address found by a Scanner.
+type Deposit struct {
+	Kind           DepositKind
+	InternalUserId int64
+	Wallet         common.Address // watched receiving address
+	From           common.Address
+	Contract       common.Address // token contract, zero for ether
+	Amount         *big.Int       // in wei or token base units
+	TxHash         common.Hash
+	LogIndex       uint // index of the Transfer log, zero for ether
+	BlockNumber    uint64
+	BlockHash      common.Hash
+}
+
+// DepositHandler is called for every deposit found. Returning an error stops
+// the scan before the cursor moves past the deposit's block, so the block is
+// scanned again: deposits are delivered at least once and handlers must be
+// idempotent, e.g. keyed by (TxHash, LogIndex).
+type DepositHandler func(ctx context.Context, d Deposit) error
+
+// ChannelHandler returns a DepositHandler that sends deposits to ch.
+func ChannelHandler(ch chan<- Deposit) DepositHandler {
+	return func(ctx context.Context, d Deposit) error {
+		select {
+		case ch <- d:
+			return nil
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+	}
+}
+
+// ScannerConfig configures a Scanner.
+type ScannerConfig struct {
+	// Confirmations is the number of blocks, the including one counted, a
+	// block needs before its deposits are reported. It defaults to 1, which
+	// reports deposits of the head block; deposits of blocks reorganised away
+	// afterwards are not retracted.
+	Confirmations uint64
+	// PollInterval is how long Run sleeps once it caught up with the chain.
+	PollInterval time.Duration
+	// Tokens restricts token deposits to these contracts. When empty, Transfer
+	// events of any contract are reported.
+	Tokens []common.Address
+	// SkipEther disables the detection of native ether deposits, which needs
+	// every block body.
+	SkipEther bool
+	// Checkpoints, when set, persists the cursor under Name after every block
+	// so that Restore can resume from it.
+	Checkpoints CheckpointStore
+	Name        string
+}
+
+// Scanner walks the chain block by block from a cursor and reports deposits
+// to watched addresses. Native transfers are detected on top-level
+// transactions only; ether moved by contract internal calls is not seen.
+// When scanned blocks are reorganised away, the cursor is rewound to the
+// common ancestor and the new branch is scanned.
+type Scanner struct {
+	client  *ClientTokenEth
+	config  ScannerConfig
+	handler DepositHandler
+
+	mu      sync.RWMutex
+	watched map[common.Address]int64
+
+	cursor     uint64       // next block to scan
+	cursorHash common.Hash  // hash of the last block scanned
+	recent     []Checkpoint // last scanned blocks, oldest first
+}
+
+// NewScanner creates a scanner starting at block next, reporting deposits to
+// handler.
+func (c *ClientTokenEth) NewScanner(config ScannerConfig, next uint64, handler DepositHandler) *Scanner {
+	if config.PollInterval <= 0 {
+		config.PollInterval = defaultPollInterval
+	}
+	if config.Confirmations == 0 {
+		config.Confirmations = 1
+	}
+	return &Scanner{
+		client:  c,
+		config:  config,
+		handler: handler,
+		watched: make(map[common.Address]int64),
+		cursor:  next,
+	}
+}
+
+// Watch adds a receiving address owned by the given internal user.
+func (s *Scanner) Watch(addr common.Address, internalUserId int64) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.watched[addr] = internalUserId
+}
+
+// Unwatch stops reporting deposits to addr.
+func (s *Scanner) Unwatch(addr common.Address) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	delete(s.watched, addr)
+}
+
+func (s *Scanner) owner(addr common.Address) (int64, bool) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	id, ok := s.watched[addr]
+	return id, ok
+}
+
+func (s *Scanner) watchedTopics() []common.Hash {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	topics := make([]common.Hash, 0, len(s.watched))
+	for addr := range s.watched {
+		topics = append(topics, common.BytesToHash(addr.Bytes()))
+	}
+	return topics
+}
+
+// Cursor returns the next block to scan and the hash of the last scanned
+// one. Persisting it lets a new scanner resume where this one stopped.
+func (s *Scanner) Cursor() (next uint64, lastHash common.Hash) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.cursor, s.cursorHash
+}
+
+func (s *Scanner) advance(header *types.Header) error {
+	if s.config.Checkpoints != nil {
+		if err := s.config.Checkpoints.Save(s.config.Name, NewCheckpoint(header)); err != nil {
+			return err
+		}
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.cursor = header.Number.Uint64() + 1
+	s.cursorHash = header.Hash()
+	s.recent = append(s.recent, NewCheckpoint(header))
+	if n := len(s.recent); n > scannerWindow {
+		s.recent = append([]Checkpoint(nil), s.recent[n-scannerWindow:]...)
+	}
+	return nil
+}
+
+// rewind moves the cursor back after the newest scanned block that is still
+// canonical. It reports false if the last scanned block still is, so there is
+// nothing to rescan, and returns ErrReorgTooDeep if none of the remembered
+// blocks is.
+func (s *Scanner) rewind(ctx context.Context) (bool, error) {
+	s.mu.RLock()
+	recent := s.recent
+	s.mu.RUnlock()
+
+	for i := len(recent) - 1; i >= 0; i-- {
+		cp := recent[i]
+		header, err := s.client.HeaderByNumber(ctx, new(big.Int).SetUint64(cp.Number))
+		if err != nil {
+			return false, err
+		}
+		if header.Hash() != cp.Hash {
+			continue
+		}
+		if i == len(recent)-1 {
+			return false, nil
+		}
+		log.Warn("Scanned blocks reorganised away, rescanning", "ancestor", cp.Number, "depth", len(recent)-1-i)
+		if s.config.Checkpoints != nil {
+			if err := s.config.Checkpoints.Save(s.config.Name, NewCheckpoint(header)); err != nil {
+				return false, err
+			}
+		}
+		s.mu.Lock()
+		defer s.mu.Unlock()
+		s.cursor, s.cursorHash = cp.Number+1, cp.Hash
+		s.recent = recent[:i+1]
+		return true, nil
+	}
+	return false, ErrReorgTooDeep
+}
+
+// Restore moves the cursor to the checkpoint saved under the configured name,
+// if any. ErrCheckpointNotCanonical is returned when the checkpoint block was
+// reorganised away since; the caller must then rewind to a block it trusts.
+func (s *Scanner) Restore(ctx context.Context) error {
+	if s.config.Checkpoints == nil {
+		return nil
+	}
+	cp, err := s.config.Checkpoints.Load(s.config.Name)
+	if err == ErrCheckpointNotFound {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	if err := s.client.VerifyCheckpoint(ctx, cp); err != nil {
+		return err
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.cursor, s.cursorHash = cp.Number+1, cp.Hash
+	s.recent = []Checkpoint{cp}
+	return nil
+}
+
+// Run restores the checkpoint, if configured, then scans until ctx is
+// cancelled, a handler fails or a reorg is deeper than the blocks the scanner
+// remembers.
+func (s *Scanner) Run(ctx context.Context) error {
+	if err := s.Restore(ctx); err != nil {
+		return err
+	}
+	for {
+		n, err := s.ScanOnce(ctx)
+		if err != nil {
+			return err
+		}
+		if n > 0 {
+			continue
+		}
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-time.After(s.config.PollInterval):
+		}
+	}
+}
+
+// ScanOnce scans every sufficiently confirmed block from the cursor and
+// returns how many blocks were scanned. Blocks orphaned by a reorg are
+// replaced by the new branch, whose deposits are reported; ErrReorgTooDeep is
+// returned if the common ancestor is older than the remembered blocks, and
+// the caller must then restart from a block it trusts.
+func (s *Scanner) ScanOnce(ctx context.Context) (int, error) {
+	head, err := s.client.HeaderByNumber(ctx, nil)
+	if err != nil {
+		return 0, err
+	}
+	if head.Number.Uint64()+1 < s.config.Confirmations {
+		return 0, nil
+	}
+	safe := head.Number.Uint64() + 1 - s.config.Confirmations
+
+	scanned := 0
+	for {
+		next, _ := s.Cursor()
+		if next > safe {
+			return scanned, nil
+		}
+		err := s.scanBlock(ctx, next)
+		if err == errScannerReorg {
+			rewound, err := s.rewind(ctx)
+			if err != nil {
+				return scanned, err
+			}
+			if !rewound {
+				// The node disagrees with itself, e.g. behind a load balancer;
+				// retry on the next scan.
+				return scanned, nil
+			}
+			continue
+		}
+		if err != nil {
+			return scanned, err
+		}
+		scanned++
+	}
+}
+
+// scanBlock reports the deposits of a single block, then moves the cursor.
+func (s *Scanner) scanBlock(ctx context.Context, number uint64) error {
+	var (
+		header *types.Header
+		block  *types.Block
+		err    error
+	)
+	if s.config.SkipEther {
+		header, err = s.client.HeaderByNumber(ctx, new(big.Int).SetUint64(number))
+	} else {
+		block, err = s.client.BlockByNumber(ctx, new(big.Int).SetUint64(number))
+		if err == nil {
+			header = block.Header()
+		}
+	}
+	if err != nil {
+		return err
+	}
+	// The previous block must still be the parent, or it was reorganised away
+	// although it had the required confirmations.
+	if _, last := s.Cursor(); last != (common.Hash{}) && header.ParentHash != last {
+		return errScannerReorg
+	}
+
+	var deposits []Deposit
+	if block != nil {
+		if deposits, err = s.etherDeposits(ctx, block); err != nil {
+			return err
+		}
+	}
+	tokens, err := s.tokenDeposits(ctx, header)
+	if err != nil {
+		return err
+	}
+	for _, d := range append(deposits, tokens...) {
+		if err := s.handler(ctx, d); err != nil {
+			return err
+		}
+	}
+	return s.advance(header)
+}
+
+func (s *Scanner) etherDeposits(ctx context.Context, block *types.Block) ([]Deposit, error) {
+	signer, err := s.client.Signer(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	var deposits []Deposit
+	for _, tx := range block.Transactions() {
+		if tx.To() == nil || tx.Value().Sign() == 0 {
+			continue
+		}
+		userID, ok := s.owner(*tx.To())
+		if !ok {
+			continue
+		}
+		// Reverted transactions move no ether.
+		receipt, err := s.client.TransactionReceipt(ctx, tx.Hash())
+		if err != nil {
+			return nil, err
+		}
+		if receipt.Status != types.ReceiptStatusSuccessful {
+			continue
+		}
+		from, err := types.Sender(signer, tx)
+		if err != nil {
+			return nil, err
+		}
+		deposits = append(deposits, Deposit{
+			Kind:           DepositETH,
+			InternalUserId: userID,
+			Wallet:         *tx.To(),
+			From:           from,
+			Amount:         new(big.Int).Set(tx.Value()),
+			TxHash:         tx.Hash(),
+			BlockNumber:    block.NumberU64(),
+			BlockHash:      block.Hash(),
+		})
+	}
+	return deposits, nil
+}
+
+func (s *Scanner) tokenDeposits(ctx context.Context, header *types.Header) ([]Deposit, error) {
+	recipients := s.watchedTopics()
+	if len(recipients) == 0 {
+		return nil, nil
+	}
+	parsed, err := parsedTokenABI()
+	if err != nil {
+		return nil, err
+	}
+	transfer := parsed.Events["Transfer"]
+
+	hash := header.Hash()
+	var logs []types.Log
+	for start := 0; start < len(recipients); start += maxTopicAddresses {
+		end := min(start+maxTopicAddresses, len(recipients))
+		chunk, err := s.client.FilterLogs(ctx, ethereum.FilterQuery{
+			BlockHash: &hash,
+			Addresses: s.config.Tokens,
+			Topics:    [][]common.Hash{{transfer.ID}, nil, recipients[start:end]},
+		})
+		if err != nil {
+			return nil, err
+		}
+		logs = append(logs, chunk...)
+	}
+	// Every log matches a single chunk; restore the block order.
+	sort.Slice(logs, func(i, j int) bool { return logs[i].Index < logs[j].Index })
+
+	var deposits []Deposit
+	for _, l := range logs {
+		// ERC-721 Transfer shares the signature but indexes the token ID.
+		if len(l.Topics) != 3 || l.Removed {
+			continue
+		}
+		to := common.BytesToAddress(l.Topics[2].Bytes())
+		userID, ok := s.owner(to)
+		if !ok {
+			continue
+		}
+		values, err := transfer.Inputs.NonIndexed().Unpack(l.Data)
+		if err != nil || len(values) != 1 {
+			log.Debug("Skipping malformed Transfer log", "tx", l.TxHash, "index", l.Index, "err", err)
+			continue
+		}
+		amount, ok := values[0].(*big.Int)
+		if !ok {
+			continue
+		}
+		deposits = append(deposits, Deposit{
+			Kind:           DepositToken,
+			InternalUserId: userID,
+			Wallet:         to,
+			From:           common.BytesToAddress(l.Topics[1].Bytes()),
+			Contract:       l.Address,
+			Amount:         amount,
+			TxHash:         l.TxHash,
+			LogIndex:       l.Index,
+			BlockNumber:    l.BlockNumber,
+			BlockHash:      l.BlockHash,
+		})
+	}
+	return deposits, nil
+}
@@ -0,0 +1,319 @@
+package eth
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"math/big"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/crypto"
+	ethrpc "github.com/ethereum/go-ethereum/rpc"
+)
+
+// scanService serves full blocks, receipts and logs for the scanner.
+type scanService struct {
+	*chainService
+	chainID  *big.Int // nil fails eth_chainId
+	txs      map[uint64][]*types.Transaction
+	receipts map[common.Hash]*types.Receipt
+	logs     []types.Log
+
+	filters []int // number of recipients in every eth_getLogs call
+}
+
+func (s *scanService) ChainId() (*hexutil.Big, error) {
+	if s.chainID == nil {
+		return nil, errReverted
+	}
+	return (*hexutil.Big)(s.chainID), nil
+}
+
+func (s *scanService) GetBlockByNumber(number ethrpc.BlockNumber, full bool) (map[string]interface{}, error) {
+	if number < 0 {
+		number = ethrpc.BlockNumber(len(s.headers) - 1)
+	}
+	if int(number) >= len(s.headers) {
+		return nil, nil
+	}
+	header := s.headers[number]
+	block, err := toJSONMap(header)
+	if err != nil {
+		return nil, err
+	}
+	txs := make([]interface{}, 0)
+	for _, tx := range s.txs[uint64(number)] {
+		if !full {
+			txs = append(txs, tx.Hash())
+			continue
+		}
+		enc, err := toJSONMap(tx)
+		if err != nil {
+			return nil, err
+		}
+		enc["blockHash"], enc["blockNumber"] = header.Hash(), (*hexutil.Big)(header.Number)
+		txs = append(txs, enc)
+	}
+	block["transactions"], block["uncles"] = txs, []common.Hash{}
+	return block, nil
+}
+
+func (s *scanService) GetTransactionReceipt(hash common.Hash) (*types.Receipt, error) {
+	return s.receipts[hash], nil
+}
+
+// logFilter is the part of the eth_getLogs criteria the scanner sends.
+type logFilter struct {
+	BlockHash common.Hash     `json:"blockHash"`
+	Topics    [][]common.Hash `json:"topics"`
+}
+
+func (s *scanService) GetLogs(crit logFilter) ([]types.Log, error) {
+	recipients := make(map[common.Hash]bool)
+	for _, topic := range crit.Topics[2] {
+		recipients[topic] = true
+	}
+	s.filters = append(s.filters, len(recipients))
+
+	var logs []types.Log
+	for _, l := range s.logs {
+		if l.BlockHash == crit.BlockHash && len(l.Topics) > 2 && l.Topics[0] == crit.Topics[0][0] && recipients[l.Topics[2]] {
+			logs = append(logs, l)
+		}
+	}
+	return logs, nil
+}
+
+func TestScanner(t *testing.T) {
+	var (
+		key, _   = crypto.GenerateKey()
+		sender   = crypto.PubkeyToAddress(key.PublicKey)
+		walletA  = common.HexToAddress("0x000000000000000000000000000000000000000a")
+		walletB  = common.HexToAddress("0x000000000000000000000000000000000000000b")
+		walletC  = common.HexToAddress("0x000000000000000000000000000000000000000c")
+		stranger = common.HexToAddress("0x00000000000000000000000000000000000000ff")
+		token    = common.HexToAddress("0x86fa049857e0209aa7d9e616f7eb3b3b78ecfdb0")
+		chainID  = big.NewInt(1337)
+	)
+	service := &scanService{
+		chainService: &chainService{},
+		chainID:      chainID,
+		txs:          make(map[uint64][]*types.Transaction),
+		receipts:     make(map[common.Hash]*types.Receipt),
+	}
+
+	// Block 2 pays A, pays B in a reverted transaction and pays a stranger.
+	send := func(nonce uint64, to common.Address, status uint64) *types.Transaction {
+		tx := signedTransfer(t, key, chainID, nonce, to)
+		service.txs[2] = append(service.txs[2], tx)
+		service.receipts[tx.Hash()] = &types.Receipt{Status: status, TxHash: tx.Hash(), Logs: []*types.Log{}}
+		return tx
+	}
+	paid := send(0, walletA, types.ReceiptStatusSuccessful)
+	send(1, walletB, types.ReceiptStatusFailed)
+	send(2, stranger, types.ReceiptStatusSuccessful)
+
+	for i := 0; i < 6; i++ {
+		var parent *types.Header
+		if i > 0 {
+			parent = service.headers[i-1]
+		}
+		header := testHeader(int64(i), parent)
+		if len(service.txs[uint64(i)]) > 0 {
+			header.TxHash = common.Hash{0x01}
+		}
+		service.headers = append(service.headers, header)
+	}
+
+	// Block 3 holds token transfers to C, a stranger, A and an ERC-721 one.
+	transfer := crypto.Keccak256Hash([]byte("Transfer(address,address,uint256)"))
+	block3 := service.headers[3]
+	for i, to := range []common.Address{walletC, stranger, walletA} {
+		service.logs = append(service.logs, types.Log{
+			Address:     token,
+			Topics:      []common.Hash{transfer, common.BytesToHash(sender.Bytes()), common.BytesToHash(to.Bytes())},
+			Data:        common.LeftPadBytes(big.NewInt(int64(100*(i+1))).Bytes(), 32),
+			BlockNumber: 3,
+			BlockHash:   block3.Hash(),
+			Index:       uint(i),
+		})
+	}
+	service.logs = append(service.logs, types.Log{
+		Address:     token,
+		Topics:      []common.Hash{transfer, common.BytesToHash(sender.Bytes()), common.BytesToHash(walletA.Bytes()), {0x01}},
+		BlockNumber: 3,
+		BlockHash:   block3.Hash(),
+		Index:       3,
+	})
+
+	c := newTestClient(t, map[string]interface{}{"eth": service})
+	var deposits []Deposit
+	scanner := c.NewScanner(ScannerConfig{Confirmations: 2}, 0, func(ctx context.Context, d Deposit) error {
+		deposits = append(deposits, d)
+		return nil
+	})
+	scanner.Watch(walletA, 1)
+	scanner.Watch(walletB, 2)
+	scanner.Watch(walletC, 3)
+	// Enough watched addresses to split the topics filter.
+	for i := 0; i < maxTopicAddresses; i++ {
+		scanner.Watch(common.BigToAddress(big.NewInt(int64(0x1000+i))), 100)
+	}
+
+	n, err := scanner.ScanOnce(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if n != 5 {
+		t.Fatalf("scanned block count mismatch: have %d, want 5", n)
+	}
+	if next, last := scanner.Cursor(); next != 5 || last != service.headers[4].Hash() {
+		t.Fatalf("cursor mismatch: have %d %x", next, last)
+	}
+
+	want := []Deposit{
+		{Kind: DepositETH, InternalUserId: 1, Wallet: walletA, From: sender, Amount: paid.Value(), TxHash: paid.Hash(), BlockNumber: 2, BlockHash: service.headers[2].Hash()},
+		{Kind: DepositToken, InternalUserId: 3, Wallet: walletC, From: sender, Contract: token, Amount: big.NewInt(100), LogIndex: 0, BlockNumber: 3, BlockHash: block3.Hash()},
+		{Kind: DepositToken, InternalUserId: 1, Wallet: walletA, From: sender, Contract: token, Amount: big.NewInt(300), LogIndex: 2, BlockNumber: 3, BlockHash: block3.Hash()},
+	}
+	if len(deposits) != len(want) {
+		t.Fatalf("deposit count mismatch: have %d, want %d: %+v", len(deposits), len(want), deposits)
+	}
+	for i, d := range deposits {
+		w := want[i]
+		if d.Kind != w.Kind || d.InternalUserId != w.InternalUserId || d.Wallet != w.Wallet || d.From != w.From ||
+			d.Contract != w.Contract || d.Amount.Cmp(w.Amount) != 0 || d.LogIndex != w.LogIndex ||
+			d.BlockNumber != w.BlockNumber || d.BlockHash != w.BlockHash {
+			t.Errorf("deposit %d mismatch:\nhave %+v\nwant %+v", i, d, w)
+		}
+		if w.Kind == DepositETH && d.TxHash != w.TxHash {
+			t.Errorf("deposit %d tx mismatch: have %x, want %x", i, d.TxHash, w.TxHash)
+		}
+	}
+
+	// Every block was filtered in two chunks within the limit.
+	if len(service.filters) != 2*5 {
+		t.Fatalf("eth_getLogs call count mismatch: have %d, want %d", len(service.filters), 2*5)
+	}
+	for _, n := range service.filters {
+		if n > maxTopicAddresses {
+			t.Fatalf("filter with %d addresses exceeds the limit of %d", n, maxTopicAddresses)
+		}
+	}
+}
+
+func TestScannerChainIDFailure(t *testing.T) {
+	service := &scanService{chainService: &chainService{headers: testChain(3)}}
+	c := newTestClient(t, map[string]interface{}{"eth": service})
+	scanner := c.NewScanner(ScannerConfig{}, 0, func(context.Context, Deposit) error { return nil })
+
+	if _, err := scanner.ScanOnce(context.Background()); err == nil {
+		t.Fatal("expected error without a chain ID")
+	}
+	if next, _ := scanner.Cursor(); next != 0 {
+		t.Fatalf("cursor moved to %d without a signer", next)
+	}
+}
+
+func TestScannerReorg(t *testing.T) {
+	var (
+		wallet = common.HexToAddress("0x000000000000000000000000000000000000000a")
+		token  = common.HexToAddress("0x86fa049857e0209aa7d9e616f7eb3b3b78ecfdb0")
+		store  = NewMemoryCheckpointStore()
+	)
+	service := &scanService{chainService: &chainService{headers: testChain(6)}}
+	c := newTestClient(t, map[string]interface{}{"eth": service})
+	var deposits []Deposit
+	scanner := c.NewScanner(ScannerConfig{SkipEther: true, Checkpoints: store, Name: "scanner"}, 0, func(ctx context.Context, d Deposit) error {
+		deposits = append(deposits, d)
+		return nil
+	})
+	scanner.Watch(wallet, 1)
+	if _, err := scanner.ScanOnce(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+
+	// Blocks 4 and 5 are replaced by a longer branch paying the wallet in 5.
+	for i := 4; i < 7; i++ {
+		header := testHeader(int64(i), service.headers[i-1])
+		header.Extra = []byte("fork")
+		if i < len(service.headers) {
+			service.headers[i] = header
+		} else {
+			service.headers = append(service.headers, header)
+		}
+	}
+	transfer := crypto.Keccak256Hash([]byte("Transfer(address,address,uint256)"))
+	service.logs = append(service.logs, types.Log{
+		Address:     token,
+		Topics:      []common.Hash{transfer, {}, common.BytesToHash(wallet.Bytes())},
+		Data:        common.LeftPadBytes(big.NewInt(100).Bytes(), 32),
+		BlockNumber: 5,
+		BlockHash:   service.headers[5].Hash(),
+	})
+
+	n, err := scanner.ScanOnce(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if n != 3 {
+		t.Fatalf("rescanned block count mismatch: have %d, want 3", n)
+	}
+	if next, last := scanner.Cursor(); next != 7 || last != service.headers[6].Hash() {
+		t.Fatalf("cursor mismatch: have %d %x", next, last)
+	}
+	if len(deposits) != 1 || deposits[0].BlockHash != service.headers[5].Hash() {
+		t.Fatalf("deposits mismatch: %+v", deposits)
+	}
+	cp, err := store.Load("scanner")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if cp.Number != 6 || cp.Hash != service.headers[6].Hash() {
+		t.Fatalf("checkpoint mismatch: have %d %x", cp.Number, cp.Hash)
+	}
+}
+
+func TestScannerReorgTooDeep(t *testing.T) {
+	service := &scanService{chainService: &chainService{headers: testChain(4)}}
+	c := newTestClient(t, map[string]interface{}{"eth": service})
+	scanner := c.NewScanner(ScannerConfig{SkipEther: true}, 2, func(context.Context, Deposit) error { return nil })
+	if _, err := scanner.ScanOnce(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+
+	// Every scanned block is replaced; the common ancestor was never scanned.
+	for i := 2; i < 5; i++ {
+		header := testHeader(int64(i), service.headers[i-1])
+		header.Extra = []byte("fork")
+		if i < len(service.headers) {
+			service.headers[i] = header
+		} else {
+			service.headers = append(service.headers, header)
+		}
+	}
+	if _, err := scanner.ScanOnce(context.Background()); err != ErrReorgTooDeep {
+		t.Fatalf("ScanOnce error mismatch: have %v, want %v", err, ErrReorgTooDeep)
+	}
+	if next, _ := scanner.Cursor(); next != 4 {
+		t.Fatalf("cursor moved to %d", next)
+	}
+}
+
+func signedTransfer(t *testing.T, key *ecdsa.PrivateKey, chainID *big.Int, nonce uint64, to common.Address) *types.Transaction {
+	tx, err := types.SignNewTx(key, types.LatestSignerForChainID(chainID), &types.DynamicFeeTx{
+		ChainID:   chainID,
+		Nonce:     nonce,
+		GasTipCap: big.NewInt(1),
+		GasFeeCap: big.NewInt(1),
+		Gas:       21000,
+		To:        &to,
+		Value:     big.NewInt(1e18),
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return tx
+}
@@ -0,0 +1,76 @@
+package eth
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+)
+
+// SenderMismatchError reports a transaction whose signature does not recover
+// to the sender claimed by the node.
+type SenderMismatchError struct {
+	Hash      common.Hash
+	Reported  common.Address
+	Recovered common.Address
+}
+
+func (e *SenderMismatchError) Error() string {
+	return fmt.Sprintf("transaction %s: node reported sender %s, signature recovers %s", e.Hash.Hex(), e.Reported.Hex(), e.Recovered.Hex())
+}
+
+// RecoverSender recomputes the sender from the v, r, s signature values with
+// the signer matching the transaction: Homestead for unprotected legacy
+// transactions, EIP-155 for protected ones and the typed transaction signers
+// for EIP-2930 and EIP-1559 ones.
+func (r *RpcEthTransaction) RecoverSender() (common.Address, error) {
+	tx, err := r.Transaction()
+	if err != nil {
+		return common.Address{}, err
+	}
+	return types.Sender(types.LatestSignerForChainID(tx.ChainId()), tx)
+}
+
+// VerifySender checks that the sender reported by the node matches the one
+// recovered from the signature, returning a *SenderMismatchError otherwise.
+func (r *RpcEthTransaction) VerifySender() error {
+	sender, err := r.RecoverSender()
+	if err != nil {
+		return err
+	}
+	if sender != r.From {
+		return &SenderMismatchError{Hash: r.Hash, Reported: r.From, Recovered: sender}
+	}
+	return nil
+}
+
+// SetVerifySenders makes TransactionByBlockNumberIndex recover the sender of
+// the transaction it returns and compare it to the one reported by the node,
+// so a misbehaving or compromised provider cannot forge senders. Other
+// transaction lookups are not checked.
+func (c *ClientTokenEth) SetVerifySenders(verify bool) {
+	c.verifySenders = verify
+}
+
+// checkSender verifies tx when sender verification is enabled. Protected
+// transactions must also be bound to the client's chain.
+func (c *ClientTokenEth) checkSender(ctx context.Context, tx *RpcEthTransaction) error {
+	if !c.verifySenders {
+		return nil
+	}
+	if err := tx.VerifySender(); err != nil {
+		log.Warn("Transaction sender verification failed", "hash", tx.Hash, "err", err)
+		return err
+	}
+	if txChainID, err := tx.GetChainID(); err == nil {
+		chainID, err := c.SignerChainID(ctx)
+		if err != nil {
+			return err
+		}
+		if txChainID.Cmp(chainID) != 0 {
+			return fmt.Errorf("transaction %s: signed for chain %v, client is on chain %v", tx.Hash.Hex(), txChainID, chainID)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,55 @@
+package eth
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/crypto"
+)
+
+func TestVerifySender(t *testing.T) {
+	key, _ := crypto.GenerateKey()
+	to := common.HexToAddress("0x86fa049857e0209aa7d9e616f7eb3b3b78ecfdb0")
+	signer := types.LatestSignerForChainID(big.NewInt(1))
+	tx, err := types.SignNewTx(key, signer, &types.DynamicFeeTx{
+		ChainID:   big.NewInt(1),
+		Nonce:     3,
+		GasTipCap: big.NewInt(1000000000),
+		GasFeeCap: big.NewInt(30000000000),
+		Gas:       21000,
+		To:        &to,
+		Value:     big.NewInt(1),
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	rt, err := NewRpcEthTransaction(tx, signer)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := rt.VerifySender(); err != nil {
+		t.Fatalf("genuine transaction rejected: %v", err)
+	}
+
+	// A forged sender is caught.
+	forged := *rt
+	forged.From = to
+	err = forged.VerifySender()
+	mismatch, ok := err.(*SenderMismatchError)
+	if !ok {
+		t.Fatalf("forged sender: have %v, want *SenderMismatchError", err)
+	}
+	if mismatch.Reported != to || mismatch.Recovered != rt.From {
+		t.Fatalf("mismatch details wrong: %+v", mismatch)
+	}
+
+	// So is a signature not matching the reported hash.
+	tampered := *rt
+	tampered.S = (*hexutil.Big)(new(big.Int).Add(rt.S.ToInt(), big.NewInt(1)))
+	if err := tampered.VerifySender(); err != ErrTxHashMismatch {
+		t.Fatalf("tampered signature: have %v, want %v", err, ErrTxHashMismatch)
+	}
+}
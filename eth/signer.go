@@ -0,0 +1,55 @@
+package eth
+
+import (
+	"context"
+	"math/big"
+
+	"github.com/ethereum/go-ethereum/core/types"
+)
+
+// SetChainID overrides the chain ID used to sign transactions. It is meant for
+// private Istanbul/Quorum networks whose nodes do not report a usable chain ID,
+// or whose network ID differs from the chain ID in the genesis config.
+// Passing nil clears the override so the next send rediscovers it.
+func (c *ClientTokenEth) SetChainID(id *big.Int) {
+	c.chainMu.Lock()
+	defer c.chainMu.Unlock()
+	if id == nil {
+		c.chainID = nil
+		return
+	}
+	c.chainID = new(big.Int).Set(id)
+}
+
+// SignerChainID returns the chain ID used for signing. The first call asks the
+// node through eth_chainId, falling back to net_version for nodes that predate
+// it, and the result is cached for the lifetime of the client.
+func (c *ClientTokenEth) SignerChainID(ctx context.Context) (*big.Int, error) {
+	c.chainMu.Lock()
+	defer c.chainMu.Unlock()
+	if c.chainID != nil {
+		return new(big.Int).Set(c.chainID), nil
+	}
+
+	id, err := c.ChainID(ctx)
+	if err != nil || id == nil || id.Sign() == 0 {
+		log.Debug("eth_chainId unavailable, falling back to net_version", "err", err)
+		id, err = c.NetworkID(ctx)
+		if err != nil {
+			return nil, err
+		}
+	}
+	c.chainID = id
+	return new(big.Int).Set(id), nil
+}
+
+// Signer returns the replay protected signer for the client's chain. It accepts
+// legacy EIP-155 transactions as well as the typed transactions introduced by
+// Berlin and London.
+func (c *ClientTokenEth) Signer(ctx context.Context) (types.Signer, error) {
+	id, err := c.SignerChainID(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return types.LatestSignerForChainID(id), nil
+}
@@ -0,0 +1,185 @@
+package eth
+
+import (
+	"encoding/json"
+	"errors"
+	"math/big"
+	"sync"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/core/types"
+	ethrpc "github.com/ethereum/go-ethereum/rpc"
+)
+
+// newTestClient serves the given RPC namespaces in-process and returns a
+// client connected to them.
+func newTestClient(t *testing.T, services map[string]interface{}) *ClientTokenEth {
+	t.Helper()
+	server := ethrpc.NewServer()
+	for name, service := range services {
+		if err := server.RegisterName(name, service); err != nil {
+			t.Fatal(err)
+		}
+	}
+	c := NewClient(ethrpc.DialInProc(server))
+	t.Cleanup(func() {
+		c.Close()
+		server.Stop()
+	})
+	return c
+}
+
+// testHeader returns a header carrying every field required on the wire.
+func testHeader(number int64, parent *types.Header) *types.Header {
+	h := &types.Header{
+		Number:      big.NewInt(number),
+		Difficulty:  big.NewInt(0),
+		GasLimit:    30000000,
+		Time:        uint64(number) * 12,
+		UncleHash:   types.EmptyUncleHash,
+		TxHash:      types.EmptyTxsHash,
+		ReceiptHash: types.EmptyReceiptsHash,
+	}
+	if parent != nil {
+		h.ParentHash = parent.Hash()
+	}
+	return h
+}
+
+// testChain returns a chain of n headers starting at genesis.
+func testChain(n int) []*types.Header {
+	headers := make([]*types.Header, n)
+	for i := range headers {
+		var parent *types.Header
+		if i > 0 {
+			parent = headers[i-1]
+		}
+		headers[i] = testHeader(int64(i), parent)
+	}
+	return headers
+}
+
+// errReverted is returned by chainService for calls it has no answer for.
+var errReverted = errors.New("execution reverted")
+
+// chainService serves the eth methods used for state reads over a fixed chain
+// and records the blocks every read was made at.
+type chainService struct {
+	headers  []*types.Header
+	balances map[common.Address]*big.Int
+	// call answers eth_call; a nil call reverts everything.
+	call func(to common.Address, input []byte) ([]byte, error)
+	// rejectHashes makes reads by block hash fail, like pre-EIP-1898 nodes.
+	rejectHashes bool
+
+	mu     sync.Mutex
+	blocks []ethrpc.BlockNumberOrHash
+}
+
+func (s *chainService) GetBlockByNumber(number ethrpc.BlockNumber, full bool) (*types.Header, error) {
+	if number < 0 {
+		return s.headers[len(s.headers)-1], nil
+	}
+	if int(number) >= len(s.headers) {
+		return nil, nil
+	}
+	return s.headers[number], nil
+}
+
+func (s *chainService) GetBlockByHash(hash common.Hash, full bool) (*types.Header, error) {
+	for _, h := range s.headers {
+		if h.Hash() == hash {
+			return h, nil
+		}
+	}
+	return nil, nil
+}
+
+func (s *chainService) Call(args map[string]interface{}, block ethrpc.BlockNumberOrHash) (hexutil.Bytes, error) {
+	if err := s.record(block); err != nil {
+		return nil, err
+	}
+	input, _ := args["input"].(string)
+	if input == "" {
+		input, _ = args["data"].(string)
+	}
+	data, err := hexutil.Decode(input)
+	if err != nil {
+		return nil, err
+	}
+	to, _ := args["to"].(string)
+	if s.call == nil {
+		return nil, errReverted
+	}
+	return s.call(common.HexToAddress(to), data)
+}
+
+func (s *chainService) GetBalance(account common.Address, block ethrpc.BlockNumberOrHash) (*hexutil.Big, error) {
+	if err := s.record(block); err != nil {
+		return nil, err
+	}
+	balance, ok := s.balances[account]
+	if !ok {
+		return nil, errors.New("unknown account")
+	}
+	return (*hexutil.Big)(balance), nil
+}
+
+func (s *chainService) record(block ethrpc.BlockNumberOrHash) error {
+	if _, ok := block.Hash(); ok && s.rejectHashes {
+		return errors.New("invalid block number")
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.blocks = append(s.blocks, block)
+	return nil
+}
+
+// readBlocks returns the blocks reads were made at since the last call.
+func (s *chainService) readBlocks() []ethrpc.BlockNumberOrHash {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	blocks := s.blocks
+	s.blocks = nil
+	return blocks
+}
+
+// tokenCalls answers eth_call with token methods through answer, which gets
+// the contract, method name and arguments of the call and returns its result.
+func tokenCalls(t *testing.T, answer func(contract common.Address, method string, args []interface{}) (interface{}, error)) func(common.Address, []byte) ([]byte, error) {
+	parsed, err := parsedTokenABI()
+	if err != nil {
+		t.Fatal(err)
+	}
+	return func(to common.Address, input []byte) ([]byte, error) {
+		if len(input) < 4 {
+			return nil, errReverted
+		}
+		method, err := parsed.MethodById(input[:4])
+		if err != nil {
+			return nil, errReverted
+		}
+		args, err := method.Inputs.Unpack(input[4:])
+		if err != nil {
+			return nil, err
+		}
+		result, err := answer(to, method.Name, args)
+		if err != nil {
+			return nil, err
+		}
+		return method.Outputs.Pack(result)
+	}
+}
+
+// toJSONMap converts v to the generic form of its JSON encoding, so fields can
+// be added to it.
+func toJSONMap(v interface{}) (map[string]interface{}, error) {
+	data, err := json.Marshal(v)
+	if err != nil {
+		return nil, err
+	}
+	var m map[string]interface{}
+	return m, json.Unmarshal(data, &m)
+}
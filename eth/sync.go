@@ -0,0 +1,74 @@
+package eth
+
+import (
+	"context"
+
+	"github.com/tokenchain/eth-client/eth/rpc"
+)
+
+// WaitForSync blocks until the node reports that it is not synchronising and
+// is connected to at least minPeers peers, logging its progress meanwhile. A
+// minPeers below one is raised to one, since a node without peers reports
+// the same as a synced one. Progress is followed through the syncing
+// subscription where available and eth_syncing is polled in any case.
+func (c *ClientTokenEth) WaitForSync(ctx context.Context, minPeers uint64) error {
+	if minPeers < 1 {
+		minPeers = 1
+	}
+	statuses := make(chan *rpc.SyncStatus, 16)
+	var subErr <-chan error
+	sub, err := c.eth.SubscribeSyncStatus(ctx, statuses)
+	if err != nil {
+		log.Debug("Sync subscription unavailable, polling instead", "err", err)
+	} else {
+		defer sub.Unsubscribe()
+		subErr = sub.Err()
+	}
+
+	// The subscription only reports sync changes, not peers, so the state is
+	// polled as well; the first tick checks it right away.
+	tick := pollTicker(ctx, defaultPollInterval)
+	for {
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case status := <-statuses:
+			if status.Syncing {
+				log.Info("Node synchronising", "current", status.Progress.CurrentBlock, "highest", status.Progress.HighestBlock,
+					"pulledStates", status.Progress.PulledStates, "knownStates", status.Progress.KnownStates)
+				continue
+			}
+		case <-tick:
+		case err := <-subErr:
+			log.Debug("Sync subscription lost, polling instead", "err", err)
+			subErr = nil
+			continue
+		}
+		ready, err := c.syncReady(ctx, minPeers)
+		if err != nil || ready {
+			return err
+		}
+	}
+}
+
+// syncReady reports whether the node is done synchronising and has enough
+// peers.
+func (c *ClientTokenEth) syncReady(ctx context.Context, minPeers uint64) (bool, error) {
+	progress, err := c.SyncProgress(ctx)
+	if err != nil {
+		return false, err
+	}
+	if progress != nil {
+		log.Info("Node synchronising", "current", progress.CurrentBlock, "highest", progress.HighestBlock)
+		return false, nil
+	}
+	peers, err := c.PeerCount(ctx)
+	if err != nil {
+		return false, err
+	}
+	if peers < minPeers {
+		log.Info("Waiting for peers", "peers", peers, "min", minPeers)
+		return false, nil
+	}
+	return true, nil
+}
@@ -0,0 +1,171 @@
+package eth
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	ethrpc "github.com/ethereum/go-ethereum/rpc"
+)
+
+// syncService serves eth_syncing and, through syncNet, net_peerCount.
+type syncService struct {
+	mu      sync.Mutex
+	syncing bool
+	peers   uint64
+}
+
+func (s *syncService) Syncing() (interface{}, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if !s.syncing {
+		return false, nil
+	}
+	return map[string]interface{}{
+		"startingBlock": hexutil.Uint64(0),
+		"currentBlock":  hexutil.Uint64(5),
+		"highestBlock":  hexutil.Uint64(10),
+	}, nil
+}
+
+func (s *syncService) set(syncing bool, peers uint64) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.syncing, s.peers = syncing, peers
+}
+
+// syncNet serves the peer count of a syncService in the net namespace.
+type syncNet struct {
+	service *syncService
+}
+
+func (n *syncNet) PeerCount() hexutil.Uint64 {
+	n.service.mu.Lock()
+	defer n.service.mu.Unlock()
+	return hexutil.Uint64(n.service.peers)
+}
+
+// syncSubscription serves the syncing subscription, sending the statuses
+// written to statuses. It shares the eth namespace with syncService.
+type syncSubscription struct {
+	statuses chan interface{}
+}
+
+func (s *syncSubscription) Syncing(ctx context.Context) (*ethrpc.Subscription, error) {
+	notifier, ok := ethrpc.NotifierFromContext(ctx)
+	if !ok {
+		return nil, ethrpc.ErrNotificationsUnsupported
+	}
+	sub := notifier.CreateSubscription()
+	go func() {
+		for {
+			select {
+			case status := <-s.statuses:
+				notifier.Notify(sub.ID, status)
+			case <-sub.Err():
+				return
+			}
+		}
+	}()
+	return sub, nil
+}
+
+// newSyncClient serves service in-process, with the syncing subscription of
+// sub unless it is nil.
+func newSyncClient(t *testing.T, service *syncService, sub *syncSubscription) *ClientTokenEth {
+	t.Helper()
+	server := ethrpc.NewServer()
+	register := func(name string, receiver interface{}) {
+		if err := server.RegisterName(name, receiver); err != nil {
+			t.Fatal(err)
+		}
+	}
+	register("eth", service)
+	register("net", &syncNet{service})
+	if sub != nil {
+		register("eth", sub)
+	}
+	c := NewClient(ethrpc.DialInProc(server))
+	t.Cleanup(func() {
+		c.Close()
+		server.Stop()
+	})
+	return c
+}
+
+// waitSync runs WaitForSync in the background.
+func waitSync(ctx context.Context, c *ClientTokenEth, minPeers uint64) <-chan error {
+	done := make(chan error, 1)
+	go func() { done <- c.WaitForSync(ctx, minPeers) }()
+	return done
+}
+
+// expectWaiting fails if WaitForSync returned.
+func expectWaiting(t *testing.T, done <-chan error) {
+	t.Helper()
+	select {
+	case err := <-done:
+		t.Fatalf("WaitForSync returned early: %v", err)
+	case <-time.After(100 * time.Millisecond):
+	}
+}
+
+func TestWaitForSync(t *testing.T) {
+	var (
+		service = &syncService{syncing: true, peers: 3}
+		sub     = &syncSubscription{statuses: make(chan interface{})}
+		c       = newSyncClient(t, service, sub)
+	)
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	done := waitSync(ctx, c, 1)
+	expectWaiting(t, done)
+
+	service.set(false, 3)
+	sub.statuses <- map[string]interface{}{"syncing": false}
+	if err := <-done; err != nil {
+		t.Fatalf("WaitForSync failed: %v", err)
+	}
+}
+
+func TestWaitForSyncPeers(t *testing.T) {
+	var (
+		service = &syncService{peers: 1}
+		sub     = &syncSubscription{statuses: make(chan interface{})}
+		c       = newSyncClient(t, service, sub)
+	)
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	done := waitSync(ctx, c, 2)
+	expectWaiting(t, done)
+
+	service.set(false, 2)
+	sub.statuses <- map[string]interface{}{"syncing": false}
+	if err := <-done; err != nil {
+		t.Fatalf("WaitForSync failed: %v", err)
+	}
+}
+
+func TestWaitForSyncPolling(t *testing.T) {
+	// Without the syncing subscription the node state is polled.
+	c := newSyncClient(t, &syncService{peers: 1}, nil)
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	if err := c.WaitForSync(ctx, 1); err != nil {
+		t.Fatalf("WaitForSync failed: %v", err)
+	}
+}
+
+func TestWaitForSyncCancel(t *testing.T) {
+	c := newSyncClient(t, &syncService{}, nil)
+	ctx, cancel := context.WithCancel(context.Background())
+	done := waitSync(ctx, c, 1)
+	expectWaiting(t, done)
+
+	cancel()
+	if err := <-done; err != context.Canceled {
+		t.Fatalf("WaitForSync error mismatch: have %v, want %v", err, context.Canceled)
+	}
+}
@@ -0,0 +1,205 @@
+package eth
+
+import (
+	"context"
+	"errors"
+	"math/big"
+	"strings"
+	"sync"
+
+	"github.com/ethereum/go-ethereum/accounts/abi"
+	"github.com/ethereum/go-ethereum/accounts/abi/bind"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+)
+
+// ErrTokenEventMissing is returned when a token transaction was mined without
+// emitting the expected event, as tokens that return false instead of
+// reverting do.
+var ErrTokenEventMissing = errors.New("token event not found in receipt")
+
+var (
+	tokenABIOnce   sync.Once
+	tokenABIParsed abi.ABI
+	tokenABIErr    error
+)
+
+// parsedTokenABI returns tokenABI parsed once for the lifetime of the process.
+func parsedTokenABI() (abi.ABI, error) {
+	tokenABIOnce.Do(func() {
+		tokenABIParsed, tokenABIErr = abi.JSON(strings.NewReader(tokenABI))
+	})
+	return tokenABIParsed, tokenABIErr
+}
+
+// PendingTokenTransaction is a handle on a broadcast ERC-20 transaction, along
+// with the event it is expected to emit.
+type PendingTokenTransaction struct {
+	*PendingTransaction
+
+	Contract common.Address
+	Event    string         // Transfer or Approval
+	Holder   common.Address // first indexed argument: from or owner
+	To       common.Address // second indexed argument: to or spender
+	Value    *big.Int
+}
+
+// Wait waits for the transaction like PendingTransaction.Wait, then checks the
+// receipt holds the expected event from the token contract.
+func (p *PendingTokenTransaction) Wait(ctx context.Context, confirmations uint64) (*types.Receipt, error) {
+	receipt, err := p.PendingTransaction.Wait(ctx, confirmations)
+	if err != nil {
+		return receipt, err
+	}
+	ok, err := p.emitted(receipt)
+	if err != nil {
+		return receipt, err
+	}
+	if !ok {
+		return receipt, ErrTokenEventMissing
+	}
+	return receipt, nil
+}
+
+// emitted reports whether receipt contains the expected event.
+func (p *PendingTokenTransaction) emitted(receipt *types.Receipt) (bool, error) {
+	parsed, err := parsedTokenABI()
+	if err != nil {
+		return false, err
+	}
+	event, ok := parsed.Events[p.Event]
+	if !ok {
+		return false, errors.New("unknown token event " + p.Event)
+	}
+	for _, l := range receipt.Logs {
+		if l.Address != p.Contract || len(l.Topics) != 3 || l.Topics[0] != event.ID {
+			continue
+		}
+		if common.BytesToAddress(l.Topics[1].Bytes()) != p.Holder || common.BytesToAddress(l.Topics[2].Bytes()) != p.To {
+			continue
+		}
+		values, err := event.Inputs.NonIndexed().Unpack(l.Data)
+		if err != nil || len(values) != 1 {
+			continue
+		}
+		if value, ok := values[0].(*big.Int); ok && value.Cmp(p.Value) == 0 {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+// SendToken transfers amount tokens, a decimal string scaled by the contract's
+// decimals, from the account of fromPriv to to.
+func (c *ClientTokenEth) SendToken(ctx context.Context, fromPriv string, tokenContract common.Address, to common.Address, amount string) (*PendingTokenTransaction, error) {
+	value, err := c.tokenAmount(ctx, tokenContract, amount)
+	if err != nil {
+		return nil, err
+	}
+	pending, err := c.sendTokenTx(ctx, fromPriv, tokenContract, func(t *tokenTransactor, opts *bind.TransactOpts) (*types.Transaction, error) {
+		return t.Transfer(opts, to, value)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &PendingTokenTransaction{
+		PendingTransaction: pending,
+		Contract:           tokenContract,
+		Event:              "Transfer",
+		Holder:             pending.From(),
+		To:                 to,
+		Value:              value,
+	}, nil
+}
+
+// ApproveToken allows spender to transfer up to amount tokens from the account
+// of ownerPriv.
+func (c *ClientTokenEth) ApproveToken(ctx context.Context, ownerPriv string, tokenContract common.Address, spender common.Address, amount string) (*PendingTokenTransaction, error) {
+	value, err := c.tokenAmount(ctx, tokenContract, amount)
+	if err != nil {
+		return nil, err
+	}
+	pending, err := c.sendTokenTx(ctx, ownerPriv, tokenContract, func(t *tokenTransactor, opts *bind.TransactOpts) (*types.Transaction, error) {
+		return t.Approve(opts, spender, value)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &PendingTokenTransaction{
+		PendingTransaction: pending,
+		Contract:           tokenContract,
+		Event:              "Approval",
+		Holder:             pending.From(),
+		To:                 spender,
+		Value:              value,
+	}, nil
+}
+
+// TransferTokenFrom moves amount tokens from from to to, using the allowance
+// from granted to the account of spenderPriv.
+func (c *ClientTokenEth) TransferTokenFrom(ctx context.Context, spenderPriv string, tokenContract common.Address, from common.Address, to common.Address, amount string) (*PendingTokenTransaction, error) {
+	value, err := c.tokenAmount(ctx, tokenContract, amount)
+	if err != nil {
+		return nil, err
+	}
+	pending, err := c.sendTokenTx(ctx, spenderPriv, tokenContract, func(t *tokenTransactor, opts *bind.TransactOpts) (*types.Transaction, error) {
+		return t.TransferFrom(opts, from, to, value)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &PendingTokenTransaction{
+		PendingTransaction: pending,
+		Contract:           tokenContract,
+		Event:              "Transfer",
+		Holder:             from,
+		To:                 to,
+		Value:              value,
+	}, nil
+}
+
+// tokenAmount scales a decimal amount by the decimals of the token contract.
+func (c *ClientTokenEth) tokenAmount(ctx context.Context, tokenContract common.Address, amount string) (*big.Int, error) {
+	caller, err := newTokenCaller(tokenContract, c)
+	if err != nil {
+		return nil, err
+	}
+	decimals, err := caller.Decimals(&bind.CallOpts{Context: ctx})
+	if err != nil {
+		return nil, err
+	}
+	return parseTokenAmount(amount, decimals.Int64())
+}
+
+// sendTokenTx builds a token transaction through the binding, which estimates
+// its gas, and hands it to sendWithNextNonce for signing and broadcast.
+func (c *ClientTokenEth) sendTokenTx(ctx context.Context, fromPriv string, tokenContract common.Address, call func(*tokenTransactor, *bind.TransactOpts) (*types.Transaction, error)) (*PendingTransaction, error) {
+	key, from, err := parsePrivateKey(fromPriv)
+	if err != nil {
+		return nil, err
+	}
+	transactor, err := newTokenTransactor(tokenContract, c)
+	if err != nil {
+		return nil, err
+	}
+	fees, err := c.SuggestFees(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return c.sendWithNextNonce(ctx, key, func(nonce uint64) (*types.Transaction, error) {
+		opts := &bind.TransactOpts{
+			From:      from,
+			Nonce:     new(big.Int).SetUint64(nonce),
+			GasPrice:  fees.GasPrice,
+			GasTipCap: fees.GasTipCap,
+			GasFeeCap: fees.GasFeeCap,
+			Context:   ctx,
+			NoSend:    true,
+			// Signing is left to sendWithNextNonce.
+			Signer: func(_ common.Address, tx *types.Transaction) (*types.Transaction, error) {
+				return tx, nil
+			},
+		}
+		return call(transactor, opts)
+	})
+}
@@ -0,0 +1,131 @@
+package eth
+
+import (
+	"context"
+	"errors"
+	"math/big"
+	"sort"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/tokenchain/eth-client/eth/rpc"
+)
+
+// ErrTxPoolUnavailable is returned when txpool_content yields no content, e.g.
+// on nodes with the namespace disabled behind a proxy.
+var ErrTxPoolUnavailable = errors.New("txpool content unavailable")
+
+// TxPool returns the bindings of the txpool namespace.
+func (c *ClientTokenEth) TxPool() rpc.TxPool {
+	return c.txpool
+}
+
+// AccountPoolReport explains where the pool transactions of an account stand.
+type AccountPoolReport struct {
+	Account common.Address
+	// Nonce is the account nonce at the latest block, the nonce of the next
+	// transaction that can be mined.
+	Nonce uint64
+	// Pending and Queued hold the pool transactions of the account, by nonce.
+	Pending []*rpc.RPCTransaction
+	Queued  []*rpc.RPCTransaction
+	// NonceGaps lists the ranges of missing nonces that keep queued
+	// transactions from becoming executable, in ascending order.
+	NonceGaps []NonceGap
+	// Underpriced holds the pending transactions likely to sit in the pool:
+	// dynamic fee ones offering a priority fee below SuggestedGasTipCap and
+	// legacy ones paying less than SuggestedGasPrice.
+	Underpriced       []*rpc.RPCTransaction
+	SuggestedGasPrice *big.Int
+	// SuggestedGasTipCap is only set when the account has pending dynamic fee
+	// transactions.
+	SuggestedGasTipCap *big.Int
+}
+
+// NonceGap is a range of consecutive missing nonces, both ends included.
+type NonceGap struct {
+	From, To uint64
+}
+
+// InspectAccountPool reports the pending and queued transactions of account,
+// the nonce gaps holding the queued ones back and the pending ones priced
+// below the node's suggestions.
+func (c *ClientTokenEth) InspectAccountPool(ctx context.Context, account common.Address) (*AccountPoolReport, error) {
+	content, err := c.txpool.Content(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if content == nil {
+		return nil, ErrTxPoolUnavailable
+	}
+	nonce, err := c.NonceAt(ctx, account, nil)
+	if err != nil {
+		return nil, err
+	}
+	suggested, err := c.SuggestGasPrice(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	report := &AccountPoolReport{
+		Account:           account,
+		Nonce:             nonce,
+		Pending:           sortedByNonce(content.Pending[account]),
+		Queued:            sortedByNonce(content.Queued[account]),
+		SuggestedGasPrice: suggested,
+	}
+	for _, tx := range report.Pending {
+		var price, floor *big.Int
+		switch {
+		case tx.GasTipCap != nil:
+			if report.SuggestedGasTipCap == nil {
+				if report.SuggestedGasTipCap, err = c.SuggestGasTipCap(ctx); err != nil {
+					return nil, err
+				}
+			}
+			price, floor = tx.GasTipCap.ToInt(), report.SuggestedGasTipCap
+		case tx.GasPrice != nil:
+			price, floor = tx.GasPrice.ToInt(), suggested
+		default:
+			continue
+		}
+		if price.Cmp(floor) < 0 {
+			report.Underpriced = append(report.Underpriced, tx)
+		}
+	}
+
+	// Every nonce from the account nonce up to the highest one in the pool must
+	// be present for the queued transactions to be promoted. The pool accepts
+	// any future nonce, so gaps are reported as ranges rather than enumerated.
+	var nonces []uint64
+	for _, txs := range [][]*rpc.RPCTransaction{report.Pending, report.Queued} {
+		for _, tx := range txs {
+			nonces = append(nonces, uint64(tx.Nonce))
+		}
+	}
+	sort.Slice(nonces, func(i, j int) bool { return nonces[i] < nonces[j] })
+	expected := nonce
+	for _, n := range nonces {
+		if n < expected {
+			continue
+		}
+		if n > expected {
+			report.NonceGaps = append(report.NonceGaps, NonceGap{From: expected, To: n - 1})
+		}
+		expected = n + 1
+	}
+	return report, nil
+}
+
+func sortedByNonce(txs map[uint64]*rpc.RPCTransaction) []*rpc.RPCTransaction {
+	nonces := make([]uint64, 0, len(txs))
+	for n := range txs {
+		nonces = append(nonces, n)
+	}
+	sort.Slice(nonces, func(i, j int) bool { return nonces[i] < nonces[j] })
+
+	sorted := make([]*rpc.RPCTransaction, 0, len(nonces))
+	for _, n := range nonces {
+		sorted = append(sorted, txs[n])
+	}
+	return sorted
+}
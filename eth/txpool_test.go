@@ -0,0 +1,118 @@
+package eth
+
+import (
+	"context"
+	"math"
+	"math/big"
+	"reflect"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/tokenchain/eth-client/eth/rpc"
+)
+
+// stubTxPool serves a fixed txpool_content.
+type stubTxPool struct {
+	rpc.TxPool
+	content *rpc.TxPoolContent
+}
+
+func (p *stubTxPool) Content(ctx context.Context) (*rpc.TxPoolContent, error) {
+	return p.content, nil
+}
+
+// poolService adds the account nonce to the fee suggestions of feeService.
+type poolService struct {
+	feeService
+	nonce uint64
+}
+
+func (s *poolService) GetTransactionCount(account common.Address, block string) hexutil.Uint64 {
+	return hexutil.Uint64(s.nonce)
+}
+
+func legacyPoolTx(nonce uint64, price int64) *rpc.RPCTransaction {
+	return &rpc.RPCTransaction{Nonce: hexutil.Uint64(nonce), GasPrice: (*hexutil.Big)(big.NewInt(price))}
+}
+
+func dynamicPoolTx(nonce uint64, tip, feeCap int64) *rpc.RPCTransaction {
+	return &rpc.RPCTransaction{
+		Nonce:     hexutil.Uint64(nonce),
+		GasPrice:  (*hexutil.Big)(big.NewInt(feeCap)),
+		GasTipCap: (*hexutil.Big)(big.NewInt(tip)),
+		GasFeeCap: (*hexutil.Big)(big.NewInt(feeCap)),
+	}
+}
+
+func TestInspectAccountPool(t *testing.T) {
+	var (
+		account = testWalletA
+		// A generous fee cap does not make up for a tip below the suggestion,
+		// and legacy prices are compared with the gas price suggestion.
+		cheapTip   = dynamicPoolTx(5, 1*gwei, 500*gwei)
+		goodTip    = dynamicPoolTx(6, 2*gwei, 30*gwei)
+		cheapPrice = legacyPoolTx(7, 10*gwei)
+		stale      = legacyPoolTx(3, 50*gwei)
+	)
+	service := &poolService{feeService: feeService{tip: 2 * gwei, price: 20 * gwei}, nonce: 5}
+	c := newTestClient(t, map[string]interface{}{"eth": service})
+	c.txpool = &stubTxPool{content: &rpc.TxPoolContent{
+		Pending: map[common.Address]map[uint64]*rpc.RPCTransaction{
+			account: {3: stale, 5: cheapTip, 6: goodTip, 7: cheapPrice},
+		},
+		Queued: map[common.Address]map[uint64]*rpc.RPCTransaction{
+			account: {10: legacyPoolTx(10, 50*gwei), 12: legacyPoolTx(12, 50*gwei)},
+		},
+	}}
+
+	report, err := c.InspectAccountPool(context.Background(), account)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if report.Nonce != 5 {
+		t.Fatalf("nonce mismatch: have %d, want 5", report.Nonce)
+	}
+	if len(report.Pending) != 4 || report.Pending[0] != stale || len(report.Queued) != 2 {
+		t.Fatalf("unexpected pool transactions: pending %d, queued %d", len(report.Pending), len(report.Queued))
+	}
+	// Nonces below the account nonce are never gaps.
+	if want := []NonceGap{{8, 9}, {11, 11}}; !reflect.DeepEqual(report.NonceGaps, want) {
+		t.Errorf("nonce gaps mismatch: have %v, want %v", report.NonceGaps, want)
+	}
+	if want := []*rpc.RPCTransaction{cheapTip, cheapPrice}; !reflect.DeepEqual(report.Underpriced, want) {
+		t.Errorf("underpriced mismatch: have %d transactions, want %d", len(report.Underpriced), len(want))
+	}
+	if report.SuggestedGasTipCap == nil || report.SuggestedGasTipCap.Int64() != 2*gwei {
+		t.Errorf("suggested tip mismatch: have %v", report.SuggestedGasTipCap)
+	}
+}
+
+func TestInspectAccountPoolFarNonce(t *testing.T) {
+	service := &poolService{feeService: feeService{tip: 2 * gwei, price: 20 * gwei}, nonce: 5}
+	c := newTestClient(t, map[string]interface{}{"eth": service})
+	far := uint64(math.MaxUint64 - 1)
+	c.txpool = &stubTxPool{content: &rpc.TxPoolContent{
+		Queued: map[common.Address]map[uint64]*rpc.RPCTransaction{
+			testWalletA: {far: legacyPoolTx(far, 50*gwei)},
+		},
+	}}
+
+	report, err := c.InspectAccountPool(context.Background(), testWalletA)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := []NonceGap{{5, far - 1}}; !reflect.DeepEqual(report.NonceGaps, want) {
+		t.Errorf("nonce gaps mismatch: have %v, want %v", report.NonceGaps, want)
+	}
+}
+
+func TestInspectAccountPoolNoContent(t *testing.T) {
+	service := &poolService{feeService: feeService{tip: 2 * gwei, price: 20 * gwei}}
+	c := newTestClient(t, map[string]interface{}{"eth": service})
+	c.txpool = &stubTxPool{}
+
+	if _, err := c.InspectAccountPool(context.Background(), testWalletA); err != ErrTxPoolUnavailable {
+		t.Fatalf("error mismatch: have %v, want %v", err, ErrTxPoolUnavailable)
+	}
+}